@@ -1,6 +1,9 @@
 package psql
 
 import (
+	"errors"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -304,6 +307,192 @@ func TestFilter(t *testing.T) {
 	})
 }
 
+func TestPermitOrderBy(t *testing.T) {
+	t.Parallel()
+
+	type sortTestStruct struct {
+		Id        int       `json:"id"`
+		Name      string    `json:"name"`
+		Password  string    `json:"password"`
+		CreatedAt time.Time `json:"created_at"`
+		Nickname  string    `json:"nickname" jsonb:"meta"`
+		Age       int       `json:"age" jsonb:"meta"`
+	}
+
+	m := NewModel(sortTestStruct{}).PermitAllExcept("Password")
+
+	tests := []struct {
+		name    string
+		sort    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			sort: "",
+			want: "",
+		},
+		{
+			name: "single field",
+			sort: "name",
+			want: "name ASC",
+		},
+		{
+			name: "direction prefixes",
+			sort: "-created_at,+name, id",
+			want: "created_at DESC, name ASC, id ASC",
+		},
+		{
+			name: "nulls first and last",
+			sort: "-created_at:nulls_last,name:NULLS_FIRST",
+			want: "created_at DESC NULLS LAST, name ASC NULLS FIRST",
+		},
+		{
+			name: "jsonb fields",
+			sort: "-age,nickname",
+			want: "(meta->>'age')::bigint DESC, meta->>'nickname' ASC",
+		},
+		{
+			name:    "field not permitted",
+			sort:    "password",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			sort:    "name,id; DROP TABLE users",
+			wantErr: true,
+		},
+		{
+			name:    "unknown option",
+			sort:    "name:random",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.OrderBy(tt.sort)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSort) {
+					t.Errorf("OrderBy() error = %v, want %v", err, ErrInvalidSort)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OrderBy() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("OrderBy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermitQuery(t *testing.T) {
+	t.Parallel()
+
+	type queryTestStruct struct {
+		Id        int        `json:"id"`
+		Name      string     `json:"name"`
+		Status    string     `json:"status"`
+		Password  string     `json:"password"`
+		Age       int        `json:"age"`
+		DeletedAt *time.Time `json:"deleted_at"`
+		Score     float64    `json:"score" jsonb:"meta"`
+	}
+
+	m := NewModel(queryTestStruct{}).PermitAllExcept("Password")
+
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:    "no params",
+			query:   "",
+			wantSQL: "SELECT id FROM query_test_structs",
+		},
+		{
+			name:     "equal and ignored params",
+			query:    "name=foo&page=2&sort=-id",
+			wantSQL:  "SELECT id FROM query_test_structs WHERE name = $1",
+			wantArgs: []interface{}{"foo"},
+		},
+		{
+			name:     "comparison operators",
+			query:    "age[gt]=30&age[lte]=60&id[ne]=1",
+			wantSQL:  "SELECT id FROM query_test_structs WHERE (age > $1) AND (age <= $2) AND (id <> $3)",
+			wantArgs: []interface{}{30, 60, 1},
+		},
+		{
+			name:     "in and not in",
+			query:    "status[in]=a,b&id[nin]=1,2",
+			wantSQL:  "SELECT id FROM query_test_structs WHERE (id NOT IN ($1, $2)) AND (status IN ($3, $4))",
+			wantArgs: []interface{}{1, 2, "a", "b"},
+		},
+		{
+			name:     "like",
+			query:    "name[ilike]=%25foo%25",
+			wantSQL:  "SELECT id FROM query_test_structs WHERE name ILIKE $1",
+			wantArgs: []interface{}{"%foo%"},
+		},
+		{
+			name:    "null",
+			query:   "deleted_at[null]=true",
+			wantSQL: "SELECT id FROM query_test_structs WHERE deleted_at IS NULL",
+		},
+		{
+			name:    "not null",
+			query:   "deleted_at[null]=0",
+			wantSQL: "SELECT id FROM query_test_structs WHERE deleted_at IS NOT NULL",
+		},
+		{
+			name:     "jsonb field",
+			query:    "score[gte]=1.5",
+			wantSQL:  "SELECT id FROM query_test_structs WHERE (meta->>'score')::numeric >= $1",
+			wantArgs: []interface{}{1.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			sql, err := m.Query(values)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			gotSQL, gotArgs := sql.Select("id").StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if len(gotArgs) != 0 || len(tt.wantArgs) != 0 {
+				if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+					t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid params", func(t *testing.T) {
+		values, _ := url.ParseQuery("age[gt]=abc&password[eq]=x&name[between]=a&age[like]=1&name=ok")
+		_, err := m.Query(values)
+		invalid, ok := err.(InvalidParamsError)
+		if !ok {
+			t.Fatalf("Query() error = %v, want InvalidParamsError", err)
+		}
+		var keys []string
+		for _, p := range invalid {
+			keys = append(keys, p.Key)
+		}
+		want := []string{"age[gt]", "age[like]", "name[between]", "password[eq]"}
+		if !reflect.DeepEqual(keys, want) {
+			t.Errorf("invalid keys = %v, want %v", keys, want)
+		}
+	})
+}
+
 func TestCreatedAt(t *testing.T) {
 	t.Parallel()
 	m := NewModel(changesTestStruct{})
@@ -456,3 +645,46 @@ func TestMustAssign(t *testing.T) {
 		m.MustAssign(target, m.FieldChanges(RawChanges{"Name": "test"}))
 	})
 }
+
+func TestNestedFieldChanges(t *testing.T) {
+	t.Parallel()
+
+	type settings struct {
+		Theme string `json:"theme"`
+		Size  int
+	}
+	type nestedStruct struct {
+		Id       int
+		Name     string
+		Settings *settings `jsonb:"meta"`
+	}
+	m := NewModel(nestedStruct{})
+
+	changes := m.FieldChanges(RawChanges{"Settings.Theme": "dark", "Settings.Size": 2, "Settings.Missing": 1, "Name.Foo": 1})
+	got := map[string]interface{}{}
+	for field, value := range changes {
+		got[field.Name+":"+field.JsonbPath] = value
+	}
+	want := map[string]interface{}{"Settings:theme": "dark", "Settings:Size": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldChanges() = %v, want %v", got, want)
+	}
+
+	sql, args := m.Insert([]Changes{changes}).StringValues()
+	if want := "INSERT INTO nested_structs (meta) VALUES ($1)"; sql != want {
+		t.Errorf("Insert SQL = %q, want %q", sql, want)
+	}
+	if want := []interface{}{`{"settings":{"Size":2,"theme":"dark"}}`}; !reflect.DeepEqual(args, want) {
+		t.Errorf("Insert args = %v, want %v", args, want)
+	}
+
+	target := nestedStruct{Name: "a", Settings: &settings{Theme: "light", Size: 1}}
+	m.MustAssign(&target, m.FieldChanges(RawChanges{"Settings.Theme": "dark", "Name": Remove}))
+	if target.Name != "" || target.Settings.Theme != "dark" || target.Settings.Size != 1 {
+		t.Errorf("target = %+v %+v", target, target.Settings)
+	}
+	m.MustAssign(&target, m.FieldChanges(RawChanges{"Settings.Size": Remove}))
+	if target.Settings.Theme != "dark" || target.Settings.Size != 0 {
+		t.Errorf("target.Settings = %+v", target.Settings)
+	}
+}
@@ -3,6 +3,7 @@ package psql
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 // Test struct for DELETE tests
@@ -57,6 +58,11 @@ func TestDeleteReturning(t *testing.T) {
 			build:   func() *DeleteSQL { return m.Delete().Returning("id", "name") },
 			wantSQL: "DELETE FROM delete_test_structs RETURNING id, name",
 		},
+		{
+			name:    "model",
+			build:   func() *DeleteSQL { return m.Delete().ReturningModel() },
+			wantSQL: "DELETE FROM delete_test_structs RETURNING delete_test_structs.id, delete_test_structs.name, delete_test_structs.status",
+		},
 	}
 
 	for _, tt := range tests {
@@ -243,3 +249,186 @@ func TestDeleteFromSelect(t *testing.T) {
 		t.Errorf("String() = %q, want %q", got, want)
 	}
 }
+
+func TestDeleteSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	type softDeleteStruct struct {
+		Id        int
+		Name      string
+		DeletedAt *time.Time `column:",softdelete"`
+	}
+	type builder interface {
+		StringValues() (string, []interface{})
+	}
+	m := NewModel(softDeleteStruct{})
+
+	if got, want := m.ColumnDataTypes()["deleted_at"], "timestamptz"; got != want {
+		t.Errorf("data type = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name     string
+		build    func() builder
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "delete",
+			build:    func() builder { return m.Delete().Where("id = $1", 1) },
+			wantSQL:  "UPDATE soft_delete_structs SET deleted_at = NOW() WHERE (id = $1) AND (deleted_at IS NULL)",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name: "delete using returning",
+			build: func() builder {
+				return m.Delete().Using("users").Where("users.id = soft_delete_structs.id").Returning("soft_delete_structs.id")
+			},
+			wantSQL:  "UPDATE soft_delete_structs SET deleted_at = NOW() FROM users WHERE (users.id = soft_delete_structs.id) AND (soft_delete_structs.deleted_at IS NULL) RETURNING soft_delete_structs.id",
+			wantArgs: []interface{}{},
+		},
+		{
+			name:     "hard delete",
+			build:    func() builder { return m.Delete().HardDelete().Where("id = $1", 1) },
+			wantSQL:  "DELETE FROM soft_delete_structs WHERE id = $1",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name:     "restore",
+			build:    func() builder { return m.Restore().Where("id = $1", 1) },
+			wantSQL:  "UPDATE soft_delete_structs SET deleted_at = NULL WHERE (id = $1) AND (deleted_at IS NOT NULL)",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name:     "find",
+			build:    func() builder { return m.Find() },
+			wantSQL:  "SELECT id, name, deleted_at FROM soft_delete_structs WHERE deleted_at IS NULL",
+			wantArgs: []interface{}{},
+		},
+		{
+			name:     "select where",
+			build:    func() builder { return m.Select("name").Where("id = $1", 1) },
+			wantSQL:  "SELECT name FROM soft_delete_structs WHERE (id = $1) AND (deleted_at IS NULL)",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name: "join",
+			build: func() builder {
+				return m.Select("name").Join("JOIN users ON users.id = soft_delete_structs.id")
+			},
+			wantSQL:  "SELECT name FROM soft_delete_structs JOIN users ON users.id = soft_delete_structs.id WHERE soft_delete_structs.deleted_at IS NULL",
+			wantArgs: []interface{}{},
+		},
+		{
+			name:     "unscoped",
+			build:    func() builder { return m.Select("name").Unscoped() },
+			wantSQL:  "SELECT name FROM soft_delete_structs",
+			wantArgs: []interface{}{},
+		},
+		{
+			name:     "only deleted",
+			build:    func() builder { return m.Select("name").OnlyDeleted() },
+			wantSQL:  "SELECT name FROM soft_delete_structs WHERE deleted_at IS NOT NULL",
+			wantArgs: []interface{}{},
+		},
+		{
+			name:     "update from find",
+			build:    func() builder { return m.Where("id = $1", 1).Update("Name", "a") },
+			wantSQL:  "UPDATE soft_delete_structs SET name = $2 WHERE (id = $1) AND (deleted_at IS NULL)",
+			wantArgs: []interface{}{1, "a"},
+		},
+		{
+			name:     "update from unscoped",
+			build:    func() builder { return m.Where("id = $1", 1).Unscoped().Update("Name", "a") },
+			wantSQL:  "UPDATE soft_delete_structs SET name = $2 WHERE id = $1",
+			wantArgs: []interface{}{1, "a"},
+		},
+		{
+			name:     "delete from find",
+			build:    func() builder { return m.Where("id = $1", 1).Delete() },
+			wantSQL:  "UPDATE soft_delete_structs SET deleted_at = NOW() WHERE (id = $1) AND (deleted_at IS NULL)",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name:     "hard delete from only deleted",
+			build:    func() builder { return m.Where("id = $1", 1).OnlyDeleted().Delete().HardDelete() },
+			wantSQL:  "DELETE FROM soft_delete_structs WHERE (id = $1) AND (deleted_at IS NOT NULL)",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name:     "find only",
+			build:    func() builder { return m.Find(Only("Name")) },
+			wantSQL:  "SELECT name FROM soft_delete_structs WHERE deleted_at IS NULL",
+			wantArgs: []interface{}{},
+		},
+		{
+			name:     "find except only deleted",
+			build:    func() builder { return m.Find(Except("DeletedAt")).OnlyDeleted() },
+			wantSQL:  "SELECT id, name FROM soft_delete_structs WHERE deleted_at IS NOT NULL",
+			wantArgs: []interface{}{},
+		},
+		{
+			name:     "update from find only",
+			build:    func() builder { return m.Find(Only("Name")).Where("id = $1", 1).Update("Name", "a") },
+			wantSQL:  "UPDATE soft_delete_structs SET name = $2 WHERE (id = $1) AND (deleted_at IS NULL)",
+			wantArgs: []interface{}{1, "a"},
+		},
+		{
+			name:     "delete from find only",
+			build:    func() builder { return m.Find(Only("Name")).Where("id = $1", 1).Delete() },
+			wantSQL:  "UPDATE soft_delete_structs SET deleted_at = NOW() WHERE (id = $1) AND (deleted_at IS NULL)",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name:     "delete from find except",
+			build:    func() builder { return m.Find(Except("DeletedAt")).Where("id = $1", 1).Delete() },
+			wantSQL:  "UPDATE soft_delete_structs SET deleted_at = NOW() WHERE (id = $1) AND (deleted_at IS NULL)",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name:     "raw sql",
+			build:    func() builder { return m.NewSQL("SELECT 1").AsSelect() },
+			wantSQL:  "SELECT 1",
+			wantArgs: []interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.build().StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if len(gotArgs) != 0 || len(tt.wantArgs) != 0 {
+				if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+					t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteAllowAll(t *testing.T) {
+	t.Parallel()
+	m := NewModel(deleteTestStruct{})
+
+	tests := []struct {
+		name  string
+		build func() *DeleteSQL
+		want  error
+	}{
+		{"no conditions", func() *DeleteSQL { return m.Delete() }, ErrNoConditions},
+		{"using without conditions", func() *DeleteSQL { return m.Delete().Using("users") }, ErrNoConditions},
+		{"where", func() *DeleteSQL { return m.Delete().Where("id = $1", 1) }, nil},
+		{"allow all", func() *DeleteSQL { return m.Delete().AllowAll() }, nil},
+		{"raw sql", func() *DeleteSQL { return m.NewSQL("DELETE FROM delete_test_structs").AsDelete() }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.build().guard(); got != tt.want {
+				t.Errorf("guard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -71,6 +71,12 @@
 // The JSONB column (metadata) is automatically created with appropriate defaults.
 // When querying, JSONB fields are automatically unmarshaled into their struct fields.
 //
+// JSONB fields can be referenced by struct field name in WHERE tuples, OrderBy
+// and FieldExpr, which translate them into ->> expressions with casts:
+//
+//	products.Find().WHERE("Price", ">", 100).OrderBy("Price DESC")
+//	// SELECT ... WHERE (metadata->>'price')::bigint > $1 ORDER BY (metadata->>'price')::bigint DESC
+//
 // # Mass Assignment Protection
 //
 // Use Permit and Filter to safely handle user input, similar to Rails strong
@@ -175,6 +181,19 @@
 //		return nil // commit; return error to rollback
 //	})
 //
+// Transactions started within a transaction block (with the block's ctx) are
+// executed as savepoints, so they can be rolled back on their own.
+//
+// Use TransactionOptsCtx to set the isolation level and access mode:
+//
+//	users.MustTransactionOptsCtx(ctx, psql.TxOptions{Isolation: psql.Serializable}, block)
+//
+// TransactionRetryCtx reruns the transaction on serialization failures and
+// deadlocks.
+//
+// Use AfterCommit and AfterRollback with the block's ctx for side effects
+// that depend on the outcome of the transaction.
+//
 // # Database Drivers
 //
 // Package psql supports multiple PostgreSQL drivers through the db.DB interface:
@@ -1,6 +1,7 @@
 package psql
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -12,6 +13,11 @@ type insertTestStruct struct {
 	Email string
 }
 
+// Test struct with only a SERIAL id for INSERT tests
+type insertIdStruct struct {
+	Id int
+}
+
 // Test struct with JSONB for INSERT tests
 type insertJsonbStruct struct {
 	Id      int
@@ -134,6 +140,13 @@ func TestInsertReturning(t *testing.T) {
 			},
 			wantSQL: "INSERT INTO insert_test_structs (name) VALUES ($1) RETURNING id AS foobar, name",
 		},
+		{
+			name: "model",
+			build: func() *InsertSQL {
+				return m.Insert("Name", "test").ReturningModel()
+			},
+			wantSQL: "INSERT INTO insert_test_structs (name) VALUES ($1) RETURNING insert_test_structs.id, insert_test_structs.name, insert_test_structs.email",
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,6 +243,105 @@ func TestInsertOnConflict(t *testing.T) {
 	}
 }
 
+func TestInsertOnConflictOptions(t *testing.T) {
+	t.Parallel()
+	m := NewModel(insertTestStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *InsertSQL
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "on constraint",
+			build: func() *InsertSQL {
+				return m.Insert("Name", "test").OnConflictConstraint("insert_test_structs_name_key").DoNothing()
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name) VALUES ($1) ON CONFLICT ON CONSTRAINT insert_test_structs_name_key DO NOTHING",
+			wantArgs: []interface{}{"test"},
+		},
+		{
+			name: "conflict where",
+			build: func() *InsertSQL {
+				return m.Insert("Name", "test").OnConflict("name").OnConflictWhere("email IS NULL").DoNothing()
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name) VALUES ($1) ON CONFLICT (name) WHERE email IS NULL DO NOTHING",
+			wantArgs: []interface{}{"test"},
+		},
+		{
+			name: "do update where",
+			build: func() *InsertSQL {
+				return m.Insert("Name", "test").OnConflict("name").DoUpdateAll().
+					Where("insert_test_structs.email <> $?", "x").Where("insert_test_structs.id > $2", 10)
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name WHERE (insert_test_structs.email <> $2) AND (insert_test_structs.id > $3)",
+			wantArgs: []interface{}{"test", "x", 10},
+		},
+		{
+			name: "do update where with returning",
+			build: func() *InsertSQL {
+				return m.Insert([]Changes{
+					m.FieldChanges(RawChanges{"Name": "a"}),
+					m.FieldChanges(RawChanges{"Name": "b"}),
+				}).OnConflictConstraint("c").DoUpdate("email = NULL").Where("insert_test_structs.email = $1", "x").Returning("id")
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name) VALUES ($1), ($2) ON CONFLICT ON CONSTRAINT c DO UPDATE SET email = NULL WHERE insert_test_structs.email = $3 RETURNING id",
+			wantArgs: []interface{}{"a", "b", "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.build().StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestInsertWhereWithoutDoUpdate(t *testing.T) {
+	t.Parallel()
+	m := NewModel(insertTestStruct{})
+
+	for name, sql := range map[string]*InsertSQL{
+		"plain insert": m.Insert("Name", "test").Where("id = $1", 1),
+		"do nothing":   m.Insert("Name", "test").OnConflict("name").DoNothing().Where("id = $1", 1),
+	} {
+		if err := sql.guard(); err != ErrNoDoUpdate {
+			t.Errorf("%s: guard() error = %v, want %v", name, err, ErrNoDoUpdate)
+		}
+	}
+	if err := m.DryRun().Insert("Name", "test").Where("id = $1", 1).Execute(); err != ErrNoDoUpdate {
+		t.Errorf("Execute() error = %v, want %v", err, ErrNoDoUpdate)
+	}
+	if err := m.Insert("Name", "test").OnConflict("name").DoUpdateAll().Where("id = $1", 1).guard(); err != nil {
+		t.Errorf("guard() error = %v, want nil", err)
+	}
+}
+
+func TestInsertOnConflictWhereWithoutTarget(t *testing.T) {
+	t.Parallel()
+	m := NewModel(insertTestStruct{})
+
+	for name, sql := range map[string]*InsertSQL{
+		"no on conflict": m.Insert("Name", "test").OnConflictWhere("email IS NULL"),
+		"no targets":     m.Insert("Name", "test").OnConflict().OnConflictWhere("email IS NULL").DoNothing(),
+		"constraint":     m.Insert("Name", "test").OnConflictConstraint("c").OnConflictWhere("email IS NULL").DoNothing(),
+	} {
+		if err := sql.guard(); err != ErrNoConflictTarget {
+			t.Errorf("%s: guard() error = %v, want %v", name, err, ErrNoConflictTarget)
+		}
+	}
+	if err := m.Insert("Name", "test").OnConflict("name").OnConflictWhere("email IS NULL").DoNothing().guard(); err != nil {
+		t.Errorf("guard() error = %v, want nil", err)
+	}
+}
+
 func TestInsertDoUpdate(t *testing.T) {
 	t.Parallel()
 	m := NewModel(insertTestStruct{})
@@ -323,6 +435,55 @@ func TestInsertWithJsonb(t *testing.T) {
 	}
 }
 
+func TestInsertMergeJSONB(t *testing.T) {
+	t.Parallel()
+
+	type mergeStruct struct {
+		Id      int
+		Picture string `jsonb:"meta"`
+		Theme   string `jsonb:"settings"`
+	}
+	m := NewModel(mergeStruct{})
+	rows := []mergeStruct{{Id: 1, Picture: "a.jpg", Theme: "dark"}}
+
+	tests := []struct {
+		name    string
+		build   func() *InsertSQL
+		wantSQL string
+	}{
+		{
+			name: "overwrite by default",
+			build: func() *InsertSQL {
+				return m.Insert(rows).OnConflict("id").DoUpdateAll()
+			},
+			wantSQL: "INSERT INTO merge_structs (id, meta, settings) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET id = EXCLUDED.id, meta = EXCLUDED.meta, settings = EXCLUDED.settings",
+		},
+		{
+			name: "merge all",
+			build: func() *InsertSQL {
+				return m.Insert(rows).OnConflict("id").DoUpdateAllExcept("id").MergeJSONB()
+			},
+			wantSQL: "INSERT INTO merge_structs (id, meta, settings) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET meta = COALESCE(merge_structs.meta, '{}'::jsonb) || EXCLUDED.meta, settings = COALESCE(merge_structs.settings, '{}'::jsonb) || EXCLUDED.settings",
+		},
+		{
+			name: "merge selected column",
+			build: func() *InsertSQL {
+				return m.Insert(rows).OnConflict("id").DoUpdateAllExcept("id").MergeJSONB("settings")
+			},
+			wantSQL: "INSERT INTO merge_structs (id, meta, settings) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET meta = EXCLUDED.meta, settings = COALESCE(merge_structs.settings, '{}'::jsonb) || EXCLUDED.settings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.build().String()
+			if got != tt.wantSQL {
+				t.Errorf("String() = %q, want %q", got, tt.wantSQL)
+			}
+		})
+	}
+}
+
 func TestInsertTap(t *testing.T) {
 	t.Parallel()
 	m := NewModel(insertTestStruct{})
@@ -363,3 +524,267 @@ func TestInsertEmpty(t *testing.T) {
 		t.Errorf("Args = %v, want empty", args)
 	}
 }
+
+func TestInsertMultiple(t *testing.T) {
+	t.Parallel()
+	m := NewModel(insertTestStruct{})
+	mj := NewModel(insertJsonbStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *InsertSQL
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "slice of structs",
+			build: func() *InsertSQL {
+				return m.Insert([]insertTestStruct{
+					{Name: "a", Email: "a@example.com"},
+					{Name: "b", Email: "b@example.com"},
+				})
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name, email) VALUES ($1, $2), ($3, $4)",
+			wantArgs: []interface{}{"a", "a@example.com", "b", "b@example.com"},
+		},
+		{
+			name: "slice of struct pointers with id",
+			build: func() *InsertSQL {
+				return m.Insert([]*insertTestStruct{
+					{Id: 1, Name: "a"},
+					{Name: "b"},
+				})
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (id, name, email) VALUES ($1, $2, $3), (DEFAULT, $4, $5)",
+			wantArgs: []interface{}{1, "a", "", "b", ""},
+		},
+		{
+			name: "slice of changes with common changes",
+			build: func() *InsertSQL {
+				return m.Insert([]Changes{
+					m.FieldChanges(RawChanges{"Name": "a"}),
+					m.FieldChanges(RawChanges{"Email": "b@example.com"}),
+				}, "Id", 5).Returning("id")
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (id, name, email) VALUES ($1, $2, DEFAULT), ($3, DEFAULT, $4) RETURNING id",
+			wantArgs: []interface{}{5, "a", 5, "b@example.com"},
+		},
+		{
+			name: "on conflict",
+			build: func() *InsertSQL {
+				return m.Insert([]insertTestStruct{{Name: "a"}}).OnConflict("name").DoUpdateAll()
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name, email) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name, email = EXCLUDED.email",
+			wantArgs: []interface{}{"a", ""},
+		},
+		{
+			name: "jsonb",
+			build: func() *InsertSQL {
+				return mj.Insert([]Changes{
+					mj.FieldChanges(RawChanges{"Picture": "a.jpg"}),
+					mj.FieldChanges(RawChanges{"Id": 2}),
+				})
+			},
+			wantSQL:  "INSERT INTO insert_jsonb_structs (id, meta) VALUES (DEFAULT, $1), ($2, DEFAULT)",
+			wantArgs: []interface{}{`{"picture":"a.jpg"}`, 2},
+		},
+		{
+			name: "empty slice",
+			build: func() *InsertSQL {
+				return m.Insert([]insertTestStruct{})
+			},
+			wantSQL: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.build().StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestInsertBatches(t *testing.T) {
+	t.Parallel()
+	m := NewModel(insertTestStruct{})
+
+	rows := make([]insertTestStruct, maxParameters/2+1)
+	for i := range rows {
+		rows[i].Name = "name"
+		rows[i].Email = "email"
+	}
+	if got := m.Insert(rows[:10]).batches(); len(got) != 1 {
+		t.Errorf("batches() = %d statements, want 1", len(got))
+	}
+	got := m.Insert(rows).batches()
+	if len(got) != 2 {
+		t.Fatalf("batches() = %d statements, want 2", len(got))
+	}
+	if n := len(got[0].values); n != maxParameters-1 {
+		t.Errorf("first statement has %d values, want %d", n, maxParameters-1)
+	}
+	want := "INSERT INTO insert_test_structs (name, email) VALUES ($1, $2)"
+	if got[1].sql != want {
+		t.Errorf("second statement = %q, want %q", got[1].sql, want)
+	}
+	if m.Insert("Name", "a").batches() != nil {
+		t.Error("batches() of a single row INSERT should be nil")
+	}
+}
+
+func TestInsertStruct(t *testing.T) {
+	t.Parallel()
+	m := NewModel(insertTestStruct{})
+	mj := NewModel(insertJsonbStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *InsertSQL
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "skip zero values with defaults",
+			build: func() *InsertSQL {
+				return m.insertStruct(reflect.ValueOf(&insertTestStruct{Name: "a"}))
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name) VALUES ($1) RETURNING id, name, email",
+			wantArgs: []interface{}{"a"},
+		},
+		{
+			name: "with id",
+			build: func() *InsertSQL {
+				return m.insertStruct(reflect.ValueOf(&insertTestStruct{Id: 3, Email: "a@example.com"}))
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (id, email) VALUES ($1, $2) RETURNING id, name, email",
+			wantArgs: []interface{}{3, "a@example.com"},
+		},
+		{
+			name: "default values",
+			build: func() *InsertSQL {
+				return m.insertStruct(reflect.ValueOf(&insertTestStruct{}))
+			},
+			wantSQL:  "INSERT INTO insert_test_structs DEFAULT VALUES RETURNING id, name, email",
+			wantArgs: []interface{}{},
+		},
+		{
+			name: "only serial id",
+			build: func() *InsertSQL {
+				return NewModel(insertIdStruct{}).insertStruct(reflect.ValueOf(&insertIdStruct{}))
+			},
+			wantSQL:  "INSERT INTO insert_id_structs DEFAULT VALUES RETURNING id",
+			wantArgs: []interface{}{},
+		},
+		{
+			name: "jsonb",
+			build: func() *InsertSQL {
+				return mj.insertStruct(reflect.ValueOf(&insertJsonbStruct{Picture: "a.jpg"}))
+			},
+			wantSQL:  "INSERT INTO insert_jsonb_structs (meta) VALUES ($1) RETURNING id, meta",
+			wantArgs: []interface{}{`{"picture":"a.jpg","tags":""}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.build().StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+
+	if err := m.InsertStruct(context.Background(), nil, insertTestStruct{}); err != ErrMustBePointer {
+		t.Errorf("InsertStruct(struct) error = %v, want ErrMustBePointer", err)
+	}
+	if err := m.InsertStruct(context.Background(), nil, &insertJsonbStruct{}); err != ErrInvalidTarget {
+		t.Errorf("InsertStruct(other struct) error = %v, want ErrInvalidTarget", err)
+	}
+}
+
+func TestInsertFrom(t *testing.T) {
+	t.Parallel()
+	m := NewModel(insertTestStruct{})
+	mj := NewModel(insertJsonbStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *InsertSQL
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "regular fields",
+			build: func() *InsertSQL {
+				return m.InsertFrom([]string{"Name", "Email"}, m.Select("name", "email").Where("id > $1", 1))
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name, email) SELECT name, email FROM insert_test_structs WHERE id > $1",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name: "jsonb fields",
+			build: func() *InsertSQL {
+				return mj.InsertFrom([]string{"Picture", "Id", "Tags"}, m.Select("email", "id", "name"))
+			},
+			wantSQL:  "INSERT INTO insert_jsonb_structs (meta, id) SELECT jsonb_build_object('picture', email, 'tags', name), id FROM insert_test_structs",
+			wantArgs: []interface{}{},
+		},
+		{
+			name: "on conflict and returning",
+			build: func() *InsertSQL {
+				return m.InsertFrom([]string{"Id", "Name"}, m.Select("id", "name").Where("name = $?", "a")).
+					OnConflict("id").DoUpdateAll().Where("insert_test_structs.name <> $1", "b").Returning("id")
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (id, name) SELECT id, name FROM insert_test_structs WHERE name = $1 ON CONFLICT (id) DO UPDATE SET id = EXCLUDED.id, name = EXCLUDED.name WHERE insert_test_structs.name <> $2 RETURNING id",
+			wantArgs: []interface{}{"a", "b"},
+		},
+		{
+			name: "unknown column names",
+			build: func() *InsertSQL {
+				return m.InsertFrom([]string{"name"}, m.NewSQL("SELECT name FROM others").AsSelect().Where("id = $1", 1))
+			},
+			wantSQL:  "INSERT INTO insert_test_structs (name) SELECT name FROM others WHERE id = $1",
+			wantArgs: []interface{}{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.build().StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestInsertFromUnmappedJsonb(t *testing.T) {
+	t.Parallel()
+	m := NewModel(insertTestStruct{})
+	mj := NewModel(insertJsonbStruct{})
+
+	for name, sql := range map[string]*InsertSQL{
+		"raw sql":        mj.InsertFrom([]string{"Id", "Picture"}, m.NewSQL("SELECT id, email FROM others").AsSelect()),
+		"count mismatch": mj.InsertFrom([]string{"Id", "Picture", "Tags"}, m.Select("id", "email")),
+		"find":           mj.InsertFrom([]string{"Id", "Picture", "Tags"}, mj.Find()),
+	} {
+		if err := sql.guard(); err == nil {
+			t.Errorf("%s: guard() error = nil, want error of unmapped JSONB field", name)
+		}
+	}
+	if err := mj.InsertFrom([]string{"Id", "Picture"}, m.Select("id", "email")).guard(); err != nil {
+		t.Errorf("guard() error = %v, want nil", err)
+	}
+}
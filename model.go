@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -30,6 +31,7 @@ type (
 		logger             logger.Logger
 		structType         reflect.Type
 		structDataTypeFunc func(Model, string) string
+		maxQueryRows       int
 		*modelInfo
 	}
 
@@ -51,6 +53,9 @@ type (
 		Exported   bool   // Exported is true if the struct field is exported (capitalized).
 		Strict     bool   // Strict enables JSON unmarshal error reporting for JSONB fields.
 		Parent     string // Parent is the parent struct path for anonymous/embedded fields.
+		Version    bool   // Version is true for the optimistic locking column (column:",version").
+		JsonbPath  string // JsonbPath is the dot-separated path of a nested key below ColumnName.
+		SoftDelete bool   // SoftDelete is true for the soft delete timestamp column (column:",softdelete").
 	}
 )
 
@@ -143,6 +148,73 @@ func (m Model) FieldByName(name string) *Field {
 	return nil
 }
 
+// FieldExpr returns the SQL expression for the struct field with the given
+// name. For regular fields this is the column name. For fields stored in a
+// JSONB column, the key is extracted with ->> and cast to the PostgreSQL type
+// derived from the field's Go type, or with -> if the field is a slice, map
+// or struct. Names that do not match any struct field are returned unchanged.
+//
+//	type User struct {
+//		Id       int
+//		FullName string `jsonb:"meta"`
+//		Age      int    `jsonb:"meta"`
+//	}
+//	users.FieldExpr("FullName") // meta->>'full_name'
+//	users.FieldExpr("Age")      // (meta->>'age')::bigint
+func (m Model) FieldExpr(name string) string {
+	if f := m.FieldByName(name); f != nil {
+		return f.expr()
+	}
+	return name
+}
+
+// tupleCondition returns the condition for a field/operator/value tuple of
+// WHERE, using $n as placeholder, and the argument for that placeholder.
+// Values compared to JSONB data as jsonb are encoded to JSON; the keys of
+// ?| and ?& are encoded to a text array.
+func (m Model) tupleCondition(name, operator string, value interface{}, n int) (string, interface{}, error) {
+	var expr string
+	var jsonb bool
+	switch operator {
+	case "@>", "<@", "?", "?|", "?&":
+		jsonb = true
+	}
+	if f := m.FieldByName(name); f == nil {
+		expr = m.ToColumnName(name)
+		jsonb = jsonb && m.isJSONBColumn(expr)
+	} else if f.Jsonb != "" && (jsonb || jsonbCastType(f.ColumnType) == "jsonb") {
+		expr = f.Jsonb + "->'" + f.ColumnName + "'"
+		jsonb = true
+	} else {
+		expr = f.expr()
+		jsonb = false
+	}
+	if !jsonb || operator == "?" {
+		return fmt.Sprintf("%s %s $%d", expr, operator, n), value, nil
+	}
+	if strings.HasPrefix(operator, "?") {
+		array, err := textArray(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s %s $%d::text[]", expr, operator, n), array, nil
+	}
+	j, err := json.Marshal(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s %s $%d::jsonb", expr, operator, n), string(j), nil
+}
+
+func (m Model) isJSONBColumn(column string) bool {
+	for _, jsonbColumn := range m.jsonbColumns {
+		if jsonbColumn == column {
+			return true
+		}
+	}
+	return false
+}
+
 // Columns returns all database column names for this Model, including JSONB
 // columns but excluding fields stored within JSONB columns.
 func (m Model) Columns() []string {
@@ -198,6 +270,14 @@ func (m Model) ColumnDataTypes() map[string]string {
 			dataTypes[f.ColumnName] = f.DataType
 			continue
 		}
+		if f.Version { // version column must not be null
+			dataTypes[f.ColumnName] = dbDataTypeFunc(f.ColumnName, strings.TrimPrefix(f.ColumnType, "*"))
+			continue
+		}
+		if f.SoftDelete { // soft delete column must be null without default
+			dataTypes[f.ColumnName] = castType(dbDataTypeFunc(f.ColumnName, f.ColumnType))
+			continue
+		}
 		dataTypes[f.ColumnName] = dbDataTypeFunc(f.ColumnName, f.ColumnType)
 	}
 	for _, jsonbField := range m.jsonbColumns {
@@ -226,7 +306,10 @@ func (m Model) ColumnDataTypes() map[string]string {
 //
 // Use the "dataType" struct tag to specify a custom PostgreSQL data type.
 // Non-pointer fields automatically include "NOT NULL". Set dataType to "-"
-// to exclude a field from schema generation.
+// to exclude a field from schema generation. The version column of
+// optimistic locking (see UpdateSQL) is always "NOT NULL" with a default of 0,
+// and the soft delete column (see DeleteSQL) is always nullable without a
+// default.
 //
 // The struct may implement BeforeCreateSchema() string to prepend SQL (e.g.,
 // CREATE EXTENSION) or AfterCreateSchema() string to append SQL (e.g.,
@@ -304,6 +387,7 @@ func (m *Model) Clone() *Model {
 		logger:             m.logger,
 		structType:         m.structType,
 		structDataTypeFunc: m.structDataTypeFunc,
+		maxQueryRows:       m.maxQueryRows,
 		modelInfo: &modelInfo{
 			columnNamer:  m.columnNamer,
 			tableName:    m.tableName,
@@ -367,6 +451,25 @@ func (m *Model) Quiet() *Model {
 	return m.Clone().SetLogger(nil)
 }
 
+// SetMaxQueryRows sets the maximum number of rows Query reads into a slice or
+// map, in total over all statements if a statement is split into several (see
+// Insert and UpdateMany). Query returns ErrTooManyRows if there are more rows.
+// Zero (the default) means no limit.
+func (m *Model) SetMaxQueryRows(n int) *Model {
+	m.maxQueryRows = n
+	return m
+}
+
+// checkMaxQueryRows increments count of rows read and returns ErrTooManyRows
+// if it exceeds the maximum.
+func (m Model) checkMaxQueryRows(count *int) error {
+	*count++
+	if m.maxQueryRows > 0 && *count > m.maxQueryRows {
+		return fmt.Errorf("%w: more than %d rows", ErrTooManyRows, m.maxQueryRows)
+	}
+	return nil
+}
+
 // SetOptions sets database connection (see SetConnection()) and/or logger (see
 // SetLogger()).
 func (m *Model) SetOptions(options ...interface{}) *Model {
@@ -429,6 +532,12 @@ func (m Model) MustExists() bool {
 	return m.MustExistsCtxTx(context.Background(), nil)
 }
 
+// MustExistsCtx is like ExistsCtx but panics if existence check operation
+// fails.
+func (m Model) MustExistsCtx(ctx context.Context) bool {
+	return m.MustExistsCtxTx(ctx, nil)
+}
+
 // MustExistsCtxTx is like ExistsCtxTx but panics if existence check operation fails.
 // Returns true if record exists, false if not exists.
 func (m Model) MustExistsCtxTx(ctx context.Context, tx Tx) bool {
@@ -445,6 +554,12 @@ func (m Model) Exists() (exists bool, err error) {
 	return m.ExistsCtxTx(context.Background(), nil)
 }
 
+// ExistsCtx is like Exists but accepts a context. The query executes within
+// the transaction of the context, if any.
+func (m Model) ExistsCtx(ctx context.Context) (bool, error) {
+	return m.ExistsCtxTx(ctx, nil)
+}
+
 // ExistsCtxTx is like Exists but accepts a context and optional transaction.
 func (m Model) ExistsCtxTx(ctx context.Context, tx Tx) (exists bool, err error) {
 	return m.newSelect().ExistsCtxTx(ctx, tx)
@@ -455,6 +570,11 @@ func (m Model) MustCount(optional ...string) int {
 	return m.MustCountCtxTx(context.Background(), nil, optional...)
 }
 
+// MustCountCtx is like CountCtx but panics if count operation fails.
+func (m Model) MustCountCtx(ctx context.Context, optional ...string) int {
+	return m.MustCountCtxTx(ctx, nil, optional...)
+}
+
 // MustCountCtxTx is like CountCtxTx but panics if count operation fails.
 func (m Model) MustCountCtxTx(ctx context.Context, tx Tx, optional ...string) int {
 	count, err := m.CountCtxTx(ctx, tx, optional...)
@@ -471,6 +591,12 @@ func (m Model) Count(optional ...string) (count int, err error) {
 	return m.CountCtxTx(context.Background(), nil, optional...)
 }
 
+// CountCtx is like Count but accepts a context. The query executes within
+// the transaction of the context, if any.
+func (m Model) CountCtx(ctx context.Context, optional ...string) (int, error) {
+	return m.CountCtxTx(ctx, nil, optional...)
+}
+
 // CountCtxTx is like Count but accepts a context and optional transaction.
 func (m Model) CountCtxTx(ctx context.Context, tx Tx, optional ...string) (count int, err error) {
 	return m.newSelect().CountCtxTx(ctx, tx, optional...)
@@ -514,6 +640,15 @@ func (m Model) Assign(target interface{}, lotsOfChanges ...interface{}) (out []i
 	for _, changes := range m.getChanges(lotsOfChanges) {
 		for field, value := range changes {
 			pointer := field.getFieldValueAddrFromStruct(rv)
+			if field.JsonbPath != "" {
+				assignNested(pointer, strings.Split(field.JsonbPath, "."), value)
+				continue
+			}
+			if _, ok := value.(remove); ok {
+				elem := reflect.ValueOf(pointer).Elem()
+				elem.Set(reflect.Zero(elem.Type()))
+				continue
+			}
 			b, _ := json.Marshal(value)
 			json.Unmarshal(b, pointer)
 		}
@@ -522,6 +657,32 @@ func (m Model) Assign(target interface{}, lotsOfChanges ...interface{}) (out []i
 	return
 }
 
+// assignNested sets (or removes if value is Remove) the nested key of the
+// JSON representation of the value that pointer points to.
+func assignNested(pointer interface{}, keys []string, value interface{}) {
+	obj := map[string]interface{}{}
+	b, _ := json.Marshal(pointer)
+	json.Unmarshal(b, &obj)
+	parent := obj
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := parent[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			parent[key] = child
+		}
+		parent = child
+	}
+	if _, ok := value.(remove); ok {
+		delete(parent, keys[len(keys)-1])
+	} else {
+		parent[keys[len(keys)-1]] = value
+	}
+	elem := reflect.ValueOf(pointer).Elem()
+	elem.Set(reflect.Zero(elem.Type()))
+	b, _ = json.Marshal(obj)
+	json.Unmarshal(b, pointer)
+}
+
 func (m Model) log(sql string, args []interface{}, elapsed time.Duration) {
 	if m.logger == nil {
 		return
@@ -549,7 +710,7 @@ func (m Model) log(sql string, args []interface{}, elapsed time.Duration) {
 	switch prefix {
 	case "DELETE", "DROP", "ROLLBACK":
 		colored = logger.RedString(sql)
-	case "INSERT", "CREATE", "COMMIT":
+	case "INSERT", "CREATE", "COMMIT", "COPY", "RELEASE":
 		colored = logger.GreenString(sql)
 	case "UPDATE", "ALTER":
 		colored = logger.YellowString(sql)
@@ -636,9 +797,16 @@ func (mi *modelInfo) parseStruct(obj interface{}, parentColumnName *string) (fie
 		}
 
 		anonymous := false
+		version := false
+		softDelete := false
 		for _, option := range columnParts[1:] {
-			if option == "anonymous" {
+			switch option {
+			case "anonymous":
 				anonymous = true
+			case "version":
+				version = true
+			case "softdelete":
+				softDelete = true
 			}
 		}
 
@@ -710,11 +878,30 @@ func (mi *modelInfo) parseStruct(obj interface{}, parentColumnName *string) (fie
 			Jsonb:      jsonb,
 			DataType:   f.Tag.Get("dataType"),
 			Strict:     strict,
+			Version:    version && jsonb == "",
+			SoftDelete: softDelete && jsonb == "",
 		})
 	}
 	return
 }
 
+// expr returns the SQL expression to read the field. Fields stored in a JSONB
+// column are extracted as text with ->> and cast to the PostgreSQL type of
+// the field's Go type, or with -> as jsonb for non-scalar types.
+func (f Field) expr() string {
+	if f.Jsonb == "" {
+		return f.ColumnName
+	}
+	switch dataType := jsonbCastType(f.ColumnType); dataType {
+	case "jsonb":
+		return f.Jsonb + "->'" + f.ColumnName + "'"
+	case "text":
+		return f.Jsonb + "->>'" + f.ColumnName + "'"
+	default:
+		return "(" + f.Jsonb + "->>'" + f.ColumnName + "')::" + dataType
+	}
+}
+
 func (f Field) getFieldValueAddrFromStruct(structValue reflect.Value) interface{} {
 	if f.Parent != "" {
 		for _, parent := range strings.Split(f.Parent, ".") {
@@ -730,3 +917,23 @@ func (f Field) getFieldValueAddrFromStruct(structValue reflect.Value) interface{
 	}
 	return reflect.NewAt(value.Type(), unsafe.Pointer(value.UnsafeAddr())).Interface()
 }
+
+// versionField returns the optimistic locking version field of the model.
+func (m Model) versionField() (Field, bool) {
+	for _, field := range m.modelFields {
+		if field.Version {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// softDeleteField returns the soft delete field of the model.
+func (m Model) softDeleteField() (Field, bool) {
+	for _, field := range m.modelFields {
+		if field.SoftDelete {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
@@ -2,9 +2,19 @@ package psql
 
 import (
 	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 )
 
+var (
+	// ErrNoPrimaryKey is returned when the model has no primary key field to
+	// identify the row of a struct.
+	ErrNoPrimaryKey = errors.New("no primary key")
+)
+
 type (
 	// RawChanges is a map of string keys to values, used as input to Filter and
 	// Changes methods. Keys should match either JSON tag names (for Changes) or
@@ -38,8 +48,18 @@ type (
 		str string
 		arg interface{}
 	}
+
+	remove struct{}
 )
 
+// Remove is a value for Update to remove the key of a field stored in a JSONB
+// column. Regular columns are set to NULL. Fields with Remove are left out of
+// Insert.
+//
+//	users.Update("Picture", psql.Remove).Where("id = $1", 1)
+//	// UPDATE users SET meta = COALESCE(meta, '{}'::jsonb) #- '{picture}' WHERE id = $1
+var Remove = remove{}
+
 // StringWithArg creates a raw SQL expression with a parameter placeholder.
 // The $? in the string will be replaced with the proper positional parameter.
 //
@@ -75,6 +95,12 @@ func (m Model) Changes(in RawChanges) (out Changes) {
 
 // FieldChanges converts RawChanges to Changes using struct field names as keys.
 // Use Changes if your keys are JSON tag names instead.
+//
+// For struct fields stored in a JSONB column, a dotted name changes only a
+// nested key of the field, so that other keys are kept on Update.
+//
+//	users.Update(users.FieldChanges(psql.RawChanges{"Settings.Theme": "dark"}))
+//	// UPDATE users SET meta = jsonb_set(..., '{settings,theme}', $1, true)
 func (m Model) FieldChanges(in RawChanges) (out Changes) {
 	out = Changes{}
 	for _, field := range m.modelFields {
@@ -83,9 +109,132 @@ func (m Model) FieldChanges(in RawChanges) (out Changes) {
 		}
 		out[field] = in[field.Name]
 	}
+	for name, value := range in {
+		if !strings.Contains(name, ".") {
+			continue
+		}
+		if field, ok := m.nestedJsonbField(name); ok {
+			out[field] = value
+		}
+	}
 	return
 }
 
+// nestedJsonbField returns the field of a dotted name like "Settings.Theme",
+// of which the first part is a field stored in a JSONB column and the others
+// are names of nested struct fields.
+func (m Model) nestedJsonbField(name string) (Field, bool) {
+	parts := strings.Split(name, ".")
+	for _, field := range m.modelFields {
+		if field.Jsonb == "" || field.Name != parts[0] {
+			continue
+		}
+		rt := m.fieldType(field)
+		var keys []string
+		for _, part := range parts[1:] {
+			for rt != nil && rt.Kind() == reflect.Ptr {
+				rt = rt.Elem()
+			}
+			if rt == nil || rt.Kind() != reflect.Struct {
+				return Field{}, false
+			}
+			sf, ok := rt.FieldByName(part)
+			if !ok {
+				return Field{}, false
+			}
+			key := sf.Name
+			if tag := strings.Split(sf.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+				key = tag
+			}
+			keys = append(keys, key)
+			rt = sf.Type
+		}
+		field.JsonbPath = strings.Join(keys, ".")
+		return field, true
+	}
+	return Field{}, false
+}
+
+// fieldType returns the Go type of the struct field of field.
+func (m Model) fieldType(field Field) reflect.Type {
+	rt := m.structType
+	if rt == nil {
+		return nil
+	}
+	names := []string{field.Name}
+	if field.Parent != "" {
+		names = append(strings.Split(field.Parent, "."), field.Name)
+	}
+	for _, name := range names {
+		for rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+		if rt.Kind() != reflect.Struct {
+			return nil
+		}
+		sf, ok := rt.FieldByName(name)
+		if !ok {
+			return nil
+		}
+		rt = sf.Type
+	}
+	return rt
+}
+
+// jsonbPath returns the keys of the path of field in its JSONB column.
+func (f Field) jsonbPath() []string {
+	if f.JsonbPath == "" {
+		return []string{f.ColumnName}
+	}
+	return append([]string{f.ColumnName}, strings.Split(f.JsonbPath, ".")...)
+}
+
+// jsonbObject returns the JSON object of the values of fields stored in the
+// same JSONB column. Values of nested keys are merged into their parents.
+// Fields with Remove are left out.
+func jsonbObject(values Changes) map[string]interface{} {
+	out := map[string]interface{}{}
+	fields := make([]Field, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sortJsonbFields(fields)
+	for _, field := range fields {
+		value := values[field]
+		if _, ok := value.(remove); ok {
+			continue
+		}
+		obj := out
+		path := field.jsonbPath()
+		for _, key := range path[:len(path)-1] {
+			child, ok := obj[key].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				if obj[key] != nil { // convert struct to map
+					b, _ := json.Marshal(obj[key])
+					json.Unmarshal(b, &child)
+				}
+				obj[key] = child
+			}
+			obj = child
+		}
+		obj[path[len(path)-1]] = value
+	}
+	return out
+}
+
+// sortJsonbFields sorts fields stored in a JSONB column by key, parents
+// first, so that changes of nested keys are applied after their parents.
+func sortJsonbFields(fields []Field) {
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].jsonbPath(), fields[j].jsonbPath()
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return strings.Join(a, ".") < strings.Join(b, ".")
+	})
+}
+
 // CreatedAt returns Changes setting the CreatedAt field to the current UTC time.
 func (m Model) CreatedAt() Changes {
 	return m.Changes(RawChanges{
@@ -120,3 +269,142 @@ func (m Model) getChanges(in []interface{}) (out []Changes) {
 	}
 	return
 }
+
+// isStructType returns true if t is the model's struct type or a pointer to it.
+func (m Model) isStructType(t reflect.Type) bool {
+	st := m.structType
+	if st == nil {
+		return false
+	}
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == st
+}
+
+// structChanges returns Changes of all fields of a struct (or pointer to
+// struct) value. Fields excluded from the schema with the "-" data type are
+// skipped, as are zero-valued fields of columns for which omitted returns true
+// (like generatesValue or hasDatabaseDefault), so that the database fills them.
+func (m Model) structChanges(rv reflect.Value, dataTypes map[string]string, omitted func(string) bool) Changes {
+	out := Changes{}
+	if rv = addressableStruct(rv); !rv.IsValid() {
+		return out
+	}
+	for _, field := range m.modelFields {
+		if field.DataType == "-" {
+			continue
+		}
+		value := reflect.ValueOf(field.getFieldValueAddrFromStruct(rv)).Elem()
+		if field.Jsonb == "" && value.IsZero() && omitted(dataTypes[field.ColumnName]) {
+			continue
+		}
+		out[field] = value.Interface()
+	}
+	return out
+}
+
+// generatesValue returns true if the data type is a SERIAL or GENERATED
+// column, whose value is generated by the database when the column is
+// omitted.
+func generatesValue(dataType string) bool {
+	dataType = strings.ToUpper(dataType)
+	return strings.Contains(dataType, "SERIAL") ||
+		strings.Contains(dataType, "GENERATED")
+}
+
+// hasDatabaseDefault returns true if the data type generates a value when
+// the column is omitted, like SERIAL, GENERATED or DEFAULT columns.
+func hasDatabaseDefault(dataType string) bool {
+	return generatesValue(dataType) || strings.Contains(strings.ToUpper(dataType), "DEFAULT")
+}
+
+// Diff compares two structs (or pointers to structs) of the model field by
+// field, including fields stored in JSONB columns, and returns the Changes of
+// fields whose values in modified differ from those in original. Values with
+// an Equal method (like time.Time) are compared with it. Fields excluded from
+// the schema with the "-" data type are skipped. ErrInvalidTarget is returned
+// if original or modified is not a struct (or non-nil pointer to a struct) of
+// the model.
+//
+//	changes, err := users.Diff(original, user)
+//	// Changes{Name: "Bob"} if only Name has been changed
+func (m Model) Diff(original, modified interface{}) (Changes, error) {
+	orig := addressableStruct(reflect.ValueOf(original))
+	mod := addressableStruct(reflect.ValueOf(modified))
+	if !orig.IsValid() || !mod.IsValid() || !m.isStructType(orig.Type()) || !m.isStructType(mod.Type()) {
+		return nil, ErrInvalidTarget
+	}
+	out := Changes{}
+	for _, field := range m.modelFields {
+		if field.DataType == "-" {
+			continue
+		}
+		a := reflect.ValueOf(field.getFieldValueAddrFromStruct(orig)).Elem()
+		b := reflect.ValueOf(field.getFieldValueAddrFromStruct(mod)).Elem()
+		if !equalValues(a, b) {
+			out[field] = b.Interface()
+		}
+	}
+	return out, nil
+}
+
+// equalValues returns true if two values of the same type are equal, using
+// their Equal method if they have one (like time.Time, whose instants may be
+// equal with different locations or monotonic clock readings), or
+// reflect.DeepEqual.
+func equalValues(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equalValues(a.Elem(), b.Elem())
+	}
+	if method := a.MethodByName("Equal"); method.IsValid() {
+		mt := method.Type()
+		if mt.NumIn() == 1 && mt.NumOut() == 1 && mt.Out(0).Kind() == reflect.Bool && b.Type().AssignableTo(mt.In(0)) {
+			return method.Call([]reflect.Value{b})[0].Bool()
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// primaryKey returns the field of the primary key column, which is the
+// column with PRIMARY KEY in its data type or else the "id" column.
+func (m Model) primaryKey() (Field, error) {
+	dataTypes := m.ColumnDataTypes()
+	for _, field := range m.modelFields {
+		if field.Jsonb == "" && strings.Contains(strings.ToUpper(dataTypes[field.ColumnName]), "PRIMARY KEY") {
+			return field, nil
+		}
+	}
+	for _, field := range m.modelFields {
+		if field.Jsonb == "" && field.ColumnName == "id" {
+			return field, nil
+		}
+	}
+	return Field{}, ErrNoPrimaryKey
+}
+
+// addressableStruct returns the addressable struct value of a struct or
+// pointer to struct, or an invalid value for anything else.
+func addressableStruct(rv reflect.Value) reflect.Value {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	if !rv.CanAddr() {
+		nv := reflect.New(rv.Type()).Elem()
+		nv.Set(rv)
+		rv = nv
+	}
+	return rv
+}
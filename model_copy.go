@@ -0,0 +1,472 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// copyFromBatchSize is the number of rows inserted per statement when the
+// connection does not support COPY FROM.
+const copyFromBatchSize = 1000
+
+var (
+	// ErrInvalidCopySource is returned when CopyFrom receives a source that is
+	// not a slice, channel or iterator of the model's struct.
+	ErrInvalidCopySource = errors.New("copy source must be a slice, channel or iterator of the model's struct")
+)
+
+type (
+	// CopyFromSource is the source of rows for COPY FROM, compatible with
+	// pgx.CopyFromSource.
+	CopyFromSource interface {
+		// Next returns true if there is another row and makes the next row
+		// data available to Values.
+		Next() bool
+		// Values returns the values for the current row.
+		Values() ([]interface{}, error)
+		// Err returns any error that has been encountered by the source.
+		Err() error
+	}
+
+	// CopyFromer is an optional interface of a connection or transaction that
+	// supports COPY FROM. CopyFrom returns the number of rows copied. It is
+	// only needed by custom connections: the connections and transactions of
+	// github.com/gopsql/pgx and github.com/gopsql/pq are supported as is.
+	CopyFromer interface {
+		CopyFrom(ctx context.Context, tableName string, columns []string, source CopyFromSource) (int64, error)
+	}
+
+	// copier copies the rows of source into the columns of a table and
+	// returns the number of rows copied.
+	copier func(ctx context.Context, tableName string, columns []string, source CopyFromSource) (int64, error)
+
+	// sqlPreparer is implemented by database/sql connections and
+	// transactions, like those of github.com/gopsql/pq.
+	sqlPreparer interface {
+		PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	}
+
+	// structCopySource is a CopyFromSource reading values of model structs.
+	structCopySource struct {
+		model        Model
+		fields       []Field
+		jsonbColumns []string
+		generated    map[string]bool
+		next         func() (reflect.Value, bool)
+		rv           reflect.Value
+		err          error
+	}
+)
+
+var (
+	contextType    = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	copySourceType = reflect.TypeOf((*structCopySource)(nil))
+)
+
+// MustCopyFrom is like CopyFrom but panics if copy operation fails.
+func (m Model) MustCopyFrom(ctx context.Context, tx Tx, source interface{}, fields ...string) int64 {
+	n, err := m.CopyFrom(ctx, tx, source, fields...)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// CopyFrom loads rows into the model's table using COPY FROM, which is much
+// faster than INSERT for large amounts of data. Source can be a slice or a
+// channel of the model's struct (or pointers to it), or an iterator function
+// like func() (User, bool) returning false when there are no more rows. If
+// tx is nil, the transaction of ctx (if any) is used.
+//
+// Fields are the struct fields to copy; fields stored in JSONB columns copy
+// their whole JSONB column. If no fields are given, all columns are copied
+// in the order of Columns, except SERIAL and GENERATED columns, which are
+// left to the database. If the source is a slice and any of its rows sets
+// such a column (like an explicit id), the column is copied too, and every
+// row must set it.
+//
+// COPY FROM is used with the connections and transactions of
+// github.com/gopsql/pgx and github.com/gopsql/pq (in a transaction, which
+// is started if needed), and with those implementing CopyFromer. Otherwise
+// rows are inserted in batches of multi-row INSERT statements in a
+// transaction. The number of rows copied is returned.
+//
+//	ch := make(chan User)
+//	go func() {
+//		defer close(ch)
+//		for _, record := range records {
+//			ch <- User{Name: record[0], Email: record[1]}
+//		}
+//	}()
+//	n, err := users.CopyFrom(ctx, nil, ch)
+func (m Model) CopyFrom(ctx context.Context, tx Tx, source interface{}, fields ...string) (int64, error) {
+	if m.connection == nil {
+		return 0, ErrNoConnection
+	}
+	src, err := m.copySource(source, fields)
+	if err != nil {
+		return 0, err
+	}
+	if tx == nil {
+		tx = txFromContext(ctx)
+	}
+	tx = m.ownTx(tx)
+	if tx != nil {
+		return m.copyTx(ctx, tx, src)
+	}
+	if c := m.copier(m.connection, false); c != nil {
+		return m.copyWith(ctx, c, src)
+	}
+	var total int64
+	err = m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) (err error) {
+		total, err = m.copyTx(ctx, tx, src)
+		return
+	})
+	return total, err
+}
+
+// copyTx copies the rows of the source in tx, with COPY FROM if tx supports
+// it, or with INSERT statements.
+func (m Model) copyTx(ctx context.Context, tx Tx, src *structCopySource) (int64, error) {
+	if c := m.copier(tx, true); c != nil {
+		return m.copyWith(ctx, c, src)
+	}
+	return m.copyByInsert(ctx, tx, src)
+}
+
+// copyWith copies the rows of the source with the copier and logs it.
+func (m Model) copyWith(ctx context.Context, c copier, src *structCopySource) (int64, error) {
+	columns := src.columns()
+	sql := "COPY " + m.tableName + " (" + strings.Join(columns, ", ") + ") FROM STDIN"
+	start := time.Now()
+	n, err := c(ctx, m.tableName, columns, src)
+	m.log(sql, nil, time.Since(start))
+	return n, err
+}
+
+// copier returns the copier of a connection or transaction, or nil if it
+// does not support COPY FROM. COPY FROM of database/sql is only supported in
+// a transaction.
+func (m Model) copier(conn interface{}, inTx bool) copier {
+	if c, ok := conn.(CopyFromer); ok {
+		return c.CopyFrom
+	}
+	if c := pgxCopier(conn); c != nil {
+		return c
+	}
+	if p, ok := conn.(sqlPreparer); ok && inTx && m.connection.DriverName() == "postgres" {
+		return copyIn(p)
+	}
+	return nil
+}
+
+// pgxCopier returns the copier of a connection or transaction with the
+// CopyFrom method of github.com/jackc/pgx (like those of
+// github.com/gopsql/pgx), or nil. The table name of the method is a
+// pgx.Identifier ([]string) and its source a pgx.CopyFromSource, which
+// structCopySource implements.
+func pgxCopier(conn interface{}) copier {
+	if conn == nil {
+		return nil
+	}
+	method := reflect.ValueOf(conn).MethodByName("CopyFrom")
+	if !method.IsValid() {
+		return nil
+	}
+	mt := method.Type()
+	if mt.NumIn() != 4 || mt.NumOut() != 2 || mt.In(0) != contextType ||
+		mt.In(1).Kind() != reflect.Slice || mt.In(1).Elem().Kind() != reflect.String ||
+		mt.In(2) != reflect.TypeOf([]string{}) || !copySourceType.AssignableTo(mt.In(3)) ||
+		mt.Out(0).Kind() != reflect.Int64 || mt.Out(1) != errorType {
+		return nil
+	}
+	return func(ctx context.Context, tableName string, columns []string, source CopyFromSource) (int64, error) {
+		out := method.Call([]reflect.Value{
+			reflect.ValueOf(&ctx).Elem(),
+			reflect.ValueOf(strings.Split(tableName, ".")).Convert(mt.In(1)),
+			reflect.ValueOf(columns),
+			reflect.ValueOf(source),
+		})
+		err, _ := out[1].Interface().(error)
+		return out[0].Int(), err
+	}
+}
+
+// copyIn returns the copier of a database/sql transaction of
+// github.com/lib/pq, which executes the values of each row with a prepared
+// COPY FROM STDIN statement, then executes it without values to finish the
+// copy.
+func copyIn(p sqlPreparer) copier {
+	return func(ctx context.Context, tableName string, columns []string, source CopyFromSource) (int64, error) {
+		stmt, err := p.PrepareContext(ctx, "COPY "+tableName+" ("+strings.Join(columns, ", ")+") FROM STDIN")
+		if err != nil {
+			return 0, err
+		}
+		defer stmt.Close()
+		var n int64
+		for source.Next() {
+			values, err := source.Values()
+			if err != nil {
+				return n, err
+			}
+			if _, err := stmt.ExecContext(ctx, values...); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if err := source.Err(); err != nil {
+			return n, err
+		}
+		_, err = stmt.ExecContext(ctx)
+		return n, err
+	}
+}
+
+// copyByInsert inserts rows of the source in batches of multi-row INSERT
+// statements in tx.
+func (m Model) copyByInsert(ctx context.Context, tx Tx, src *structCopySource) (int64, error) {
+	var total int64
+	rows := make([]Changes, 0, copyFromBatchSize)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		var n int64
+		if err := m.Insert(rows).ExecuteCtxTx(ctx, tx, &n); err != nil {
+			return err
+		}
+		total += n
+		rows = rows[:0]
+		return nil
+	}
+	for src.Next() {
+		changes, err := src.changes()
+		if err != nil {
+			return total, err
+		}
+		rows = append(rows, changes)
+		if len(rows) == copyFromBatchSize {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+	if err := src.Err(); err != nil {
+		return total, err
+	}
+	return total, flush()
+}
+
+// copySource returns the CopyFromSource of the rows of source, copying the
+// given fields (see CopyFrom).
+func (m Model) copySource(source interface{}, names []string) (*structCopySource, error) {
+	next, err := m.copyIterator(source)
+	if err != nil {
+		return nil, err
+	}
+	src := &structCopySource{
+		model:     m,
+		generated: map[string]bool{},
+		next:      next,
+	}
+	if len(names) > 0 {
+		for _, name := range names {
+			field := m.FieldByName(name)
+			if field == nil {
+				return nil, fmt.Errorf("unknown field %q", name)
+			}
+			if field.Jsonb == "" {
+				src.fields = append(src.fields, *field)
+			} else if !src.hasJsonbColumn(field.Jsonb) {
+				src.jsonbColumns = append(src.jsonbColumns, field.Jsonb)
+			}
+		}
+		return src, nil
+	}
+	rows := reflect.ValueOf(source)
+	dataTypes := m.ColumnDataTypes()
+	for _, field := range m.modelFields {
+		if field.Jsonb != "" {
+			continue
+		}
+		dataType, ok := dataTypes[field.ColumnName]
+		if !ok {
+			continue
+		}
+		if generatesValue(dataType) {
+			if rows.Kind() != reflect.Slice && rows.Kind() != reflect.Array || !anyNonZero(rows, field) {
+				continue
+			}
+			src.generated[field.Name] = true
+		}
+		src.fields = append(src.fields, field)
+	}
+	src.jsonbColumns = m.jsonbColumns
+	return src, nil
+}
+
+// anyNonZero returns true if the field is not zero in any row of a slice of
+// structs (or pointers to structs).
+func anyNonZero(rows reflect.Value, field Field) bool {
+	for i := 0; i < rows.Len(); i++ {
+		rv := addressableStruct(rows.Index(i))
+		if !rv.IsValid() {
+			continue
+		}
+		if !reflect.ValueOf(field.getFieldValueAddrFromStruct(rv)).Elem().IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// copyIterator returns a function returning the struct values of a slice,
+// channel or iterator function one by one.
+func (m Model) copyIterator(source interface{}) (func() (reflect.Value, bool), error) {
+	rv := reflect.ValueOf(source)
+	if !rv.IsValid() {
+		return nil, ErrInvalidCopySource
+	}
+	rt := rv.Type()
+	switch rt.Kind() {
+	case reflect.Slice, reflect.Array:
+		if !m.isStructType(rt.Elem()) {
+			return nil, ErrInvalidCopySource
+		}
+		i := 0
+		return func() (reflect.Value, bool) {
+			if i >= rv.Len() {
+				return reflect.Value{}, false
+			}
+			i++
+			return rv.Index(i - 1), true
+		}, nil
+	case reflect.Chan:
+		if rt.ChanDir()&reflect.RecvDir == 0 || !m.isStructType(rt.Elem()) {
+			return nil, ErrInvalidCopySource
+		}
+		return func() (reflect.Value, bool) {
+			return rv.Recv()
+		}, nil
+	case reflect.Func:
+		if rt.NumIn() != 0 || rt.NumOut() != 2 || !m.isStructType(rt.Out(0)) ||
+			rt.Out(1).Kind() != reflect.Bool {
+			return nil, ErrInvalidCopySource
+		}
+		return func() (reflect.Value, bool) {
+			out := rv.Call(nil)
+			return out[0], out[1].Bool()
+		}, nil
+	}
+	return nil, ErrInvalidCopySource
+}
+
+func (s *structCopySource) Next() bool {
+	for {
+		rv, ok := s.next()
+		if !ok {
+			return false
+		}
+		if rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				continue
+			}
+			rv = rv.Elem()
+		}
+		if !rv.CanAddr() {
+			nv := reflect.New(rv.Type()).Elem()
+			nv.Set(rv)
+			rv = nv
+		}
+		s.rv = rv
+		return true
+	}
+}
+
+func (s *structCopySource) Values() ([]interface{}, error) {
+	values := []interface{}{}
+	for _, field := range s.fields {
+		value, err := s.value(field)
+		if err != nil {
+			s.err = err
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	for _, column := range s.jsonbColumns {
+		out := map[string]interface{}{}
+		for _, field := range s.model.modelFields {
+			if field.Jsonb != column {
+				continue
+			}
+			out[field.ColumnName] = reflect.ValueOf(field.getFieldValueAddrFromStruct(s.rv)).Elem().Interface()
+		}
+		j, err := json.Marshal(out)
+		if err != nil {
+			s.err = err
+			return nil, err
+		}
+		values = append(values, string(j))
+	}
+	return values, nil
+}
+
+func (s *structCopySource) Err() error {
+	return s.err
+}
+
+// columns returns the columns copied, regular columns first.
+func (s *structCopySource) columns() []string {
+	columns := []string{}
+	for _, field := range s.fields {
+		columns = append(columns, field.ColumnName)
+	}
+	return append(columns, s.jsonbColumns...)
+}
+
+// value returns the value of a regular field of the current row. A zero
+// value of a generated column copied because other rows set it is an error,
+// as COPY FROM can't leave it to the database.
+func (s *structCopySource) value(field Field) (interface{}, error) {
+	value := reflect.ValueOf(field.getFieldValueAddrFromStruct(s.rv)).Elem()
+	if s.generated[field.Name] && value.IsZero() {
+		return nil, fmt.Errorf("column %s is generated by the database, so it must be set in every row or in none", field.ColumnName)
+	}
+	return value.Interface(), nil
+}
+
+// changes returns the Changes of the copied fields of the current row.
+func (s *structCopySource) changes() (Changes, error) {
+	changes := Changes{}
+	for _, field := range s.fields {
+		value, err := s.value(field)
+		if err != nil {
+			return nil, err
+		}
+		changes[field] = value
+	}
+	for _, field := range s.model.modelFields {
+		if field.Jsonb != "" && s.hasJsonbColumn(field.Jsonb) {
+			changes[field] = reflect.ValueOf(field.getFieldValueAddrFromStruct(s.rv)).Elem().Interface()
+		}
+	}
+	return changes, nil
+}
+
+// hasJsonbColumn returns true if the JSONB column is one of the copied
+// columns.
+func (s *structCopySource) hasJsonbColumn(column string) bool {
+	for _, c := range s.jsonbColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
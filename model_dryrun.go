@@ -0,0 +1,261 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/gopsql/db"
+)
+
+type (
+	// Statement is a SQL statement with its parameter values, as recorded by
+	// a dry run model (see Model.DryRun).
+	Statement struct {
+		SQL  string
+		Args []interface{}
+	}
+
+	// dryRunDB is a db.DB recording statements instead of executing them.
+	// EXPLAIN statements are executed by the original connection, if any.
+	dryRunDB struct {
+		conn          db.DB
+		explainTarget interface{}
+		mu            sync.Mutex
+		statements    []Statement
+	}
+
+	// dryRunTx is a db.Tx of a dryRunDB.
+	dryRunTx struct {
+		db *dryRunDB
+	}
+
+	// dryRunResult is the result of every statement executed by a dryRunDB.
+	dryRunResult struct{}
+
+	// dryRunRows are the (empty) rows of every query of a dryRunDB.
+	dryRunRows struct{}
+
+	// dryRunRow is the row of every QueryRow of a dryRunDB, which scans
+	// nothing.
+	dryRunRow struct {
+		err error
+	}
+)
+
+// DryRun returns a copy of the model whose Query, QueryRow and Execute
+// methods, and those of the statements built from it, log and record the
+// statements instead of executing them. No rows are returned and no rows are
+// affected, so targets are left untouched. Use Statements to get the recorded
+// statements. The model does not need a connection. Transactions of other
+// models, given explicitly (like to ExecuteCtxTx) or carried by the context
+// (see TransactionCtx), are ignored, so even within them nothing is executed.
+//
+// If explainTarget is given, every recorded statement is also explained by
+// the model's connection (EXPLAIN without ANALYZE, which does not execute the
+// statement) and the plan is written to the target. See SQL.Explain for the
+// supported targets.
+//
+//	dry := users.DryRun()
+//	dry.Update("Name", "Bob").Where("id = $1", 1).MustExecute()
+//	for _, stmt := range dry.Statements() {
+//		fmt.Println(stmt.SQL, stmt.Args) // UPDATE users SET name = $2 WHERE id = $1 [1 Bob]
+//	}
+func (m *Model) DryRun(explainTarget ...interface{}) *Model {
+	d := &dryRunDB{conn: m.connection}
+	if len(explainTarget) > 0 {
+		d.explainTarget = explainTarget[0]
+	}
+	if current, ok := m.connection.(*dryRunDB); ok {
+		d.conn = current.conn
+	}
+	return m.Clone().SetConnection(d)
+}
+
+// Statements returns the statements recorded by a dry run model (see DryRun),
+// or nil if the model is not a dry run model. Models cloned from a dry run
+// model share its statements.
+func (m Model) Statements() []Statement {
+	d, ok := m.connection.(*dryRunDB)
+	if !ok {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Statement{}, d.statements...)
+}
+
+// ownTx returns tx, or nil if the model is a dry run model and tx is not a
+// transaction of a dry run model, so that statements of a dry run model are
+// never executed in a real transaction, whether it is given explicitly or
+// carried by the context.
+func (m Model) ownTx(tx Tx) Tx {
+	if _, ok := tx.(dryRunTx); m.isDryRun() && !ok {
+		return nil
+	}
+	return tx
+}
+
+// isDryRun returns true if the model is a dry run model.
+func (m Model) isDryRun() bool {
+	_, ok := m.connection.(*dryRunDB)
+	return ok
+}
+
+// record records the statement and explains it if needed.
+func (d *dryRunDB) record(ctx context.Context, query string, args []interface{}) error {
+	d.mu.Lock()
+	d.statements = append(d.statements, Statement{SQL: query, Args: args})
+	d.mu.Unlock()
+	if d.explainTarget == nil || d.conn == nil {
+		return nil
+	}
+	switch strings.ToUpper(strings.SplitN(query, " ", 2)[0]) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH":
+	default:
+		return nil
+	}
+	rows, err := d.conn.QueryContext(ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return err
+	}
+	return writeExplain(d.explainTarget, rows)
+}
+
+// isExplain returns true for EXPLAIN statements without the ANALYZE option,
+// which do not execute the statement explained.
+func isExplain(query string) bool {
+	upper := strings.ToUpper(query)
+	if !strings.HasPrefix(upper, "EXPLAIN ") {
+		return false
+	}
+	options := strings.TrimSpace(upper[8:])
+	if strings.HasPrefix(options, "(") {
+		options = options[:strings.Index(options+")", ")")]
+	} else if words := strings.Fields(options); len(words) > 2 {
+		options = words[0] + " " + words[1]
+	}
+	return !strings.Contains(options, "ANALYZE")
+}
+
+func (d *dryRunDB) Close() error {
+	return nil
+}
+
+func (d *dryRunDB) Exec(query string, args ...interface{}) (db.Result, error) {
+	return d.ExecContext(context.Background(), query, args...)
+}
+
+func (d *dryRunDB) ExecContext(ctx context.Context, query string, args ...interface{}) (db.Result, error) {
+	return dryRunResult{}, d.record(ctx, query, args)
+}
+
+func (d *dryRunDB) Query(query string, args ...interface{}) (db.Rows, error) {
+	return d.QueryContext(context.Background(), query, args...)
+}
+
+func (d *dryRunDB) QueryContext(ctx context.Context, query string, args ...interface{}) (db.Rows, error) {
+	if isExplain(query) {
+		if d.conn == nil {
+			return nil, ErrNoConnection
+		}
+		return d.conn.QueryContext(ctx, query, args...)
+	}
+	return dryRunRows{}, d.record(ctx, query, args)
+}
+
+func (d *dryRunDB) QueryRow(query string, args ...interface{}) db.Row {
+	return d.QueryRowContext(context.Background(), query, args...)
+}
+
+func (d *dryRunDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) db.Row {
+	return dryRunRow{d.record(ctx, query, args)}
+}
+
+func (d *dryRunDB) BeginTx(ctx context.Context, isolationLevel string, readOnly bool) (db.Tx, error) {
+	return dryRunTx{d}, nil
+}
+
+func (d *dryRunDB) DriverName() string {
+	if d.conn == nil {
+		return ""
+	}
+	return d.conn.DriverName()
+}
+
+func (d *dryRunDB) ErrNoRows() error {
+	if d.conn == nil {
+		return sql.ErrNoRows
+	}
+	return d.conn.ErrNoRows()
+}
+
+func (d *dryRunDB) ErrGetCode(err error) string {
+	if d.conn == nil {
+		return ""
+	}
+	return d.conn.ErrGetCode(err)
+}
+
+func (d *dryRunDB) ConvertParameters(query string, args []interface{}) (string, []interface{}) {
+	if c, ok := d.conn.(db.ConvertParameters); ok {
+		return c.ConvertParameters(query, args)
+	}
+	return query, args
+}
+
+func (d *dryRunDB) FieldDataType(fieldName, fieldType string) string {
+	if c, ok := d.conn.(hasFieldDataTypeFunc); ok {
+		return c.FieldDataType(fieldName, fieldType)
+	}
+	return FieldDataType(fieldName, fieldType)
+}
+
+func (t dryRunTx) ExecContext(ctx context.Context, query string, args ...interface{}) (db.Result, error) {
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t dryRunTx) QueryContext(ctx context.Context, query string, args ...interface{}) (db.Rows, error) {
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t dryRunTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) db.Row {
+	return t.db.QueryRowContext(ctx, query, args...)
+}
+
+func (t dryRunTx) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (t dryRunTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func (dryRunResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
+
+func (dryRunRows) Close() error {
+	return nil
+}
+
+func (dryRunRows) Columns() ([]string, error) {
+	return nil, nil
+}
+
+func (dryRunRows) Err() error {
+	return nil
+}
+
+func (dryRunRows) Next() bool {
+	return false
+}
+
+func (dryRunRows) Scan(dest ...interface{}) error {
+	return nil
+}
+
+func (r dryRunRow) Scan(dest ...interface{}) error {
+	return r.err
+}
@@ -2,8 +2,14 @@ package psql
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type (
@@ -17,6 +23,47 @@ type (
 	}
 )
 
+type (
+	// InvalidParamsError is returned by ModelWithPermittedFields.Query. It
+	// lists every query parameter that could not be converted to a condition.
+	InvalidParamsError []InvalidParam
+
+	// InvalidParam describes a query parameter rejected by Query.
+	InvalidParam struct {
+		Key    string // Key is the parameter name, e.g. "age[gt]".
+		Value  string // Value is the rejected value.
+		Reason string // Reason describes why the parameter was rejected.
+	}
+)
+
+func (e InvalidParamsError) Error() string {
+	msgs := make([]string, len(e))
+	for i, p := range e {
+		msgs[i] = fmt.Sprintf("%s=%q: %s", p.Key, p.Value, p.Reason)
+	}
+	return "invalid params: " + strings.Join(msgs, "; ")
+}
+
+var queryOperators = map[string]string{
+	"eq":    "=",
+	"ne":    "<>",
+	"gt":    ">",
+	"gte":   ">=",
+	"lt":    "<",
+	"lte":   "<=",
+	"in":    "IN",
+	"nin":   "NOT IN",
+	"like":  "LIKE",
+	"ilike": "ILIKE",
+	"null":  "IS NULL",
+}
+
+var (
+	// ErrInvalidSort is returned by OrderBy when the sort parameter contains
+	// a field that is not permitted or an unknown option.
+	ErrInvalidSort = errors.New("invalid sort")
+)
+
 // Permit creates a ModelWithPermittedFields that only allows the specified
 // fields in Filter operations. This provides mass assignment protection similar
 // to Rails strong parameters. If no field names are provided, no fields are
@@ -191,3 +238,196 @@ func (m ModelWithPermittedFields) filterPermits(in RawChanges, out *Changes) {
 		(*out)[field] = x.Elem().Interface()
 	}
 }
+
+// OrderBy converts a sort parameter from user input, such as the "sort" query
+// string of an API, into an ORDER BY expression that can safely be passed to
+// SelectSQL.OrderBy. The parameter is a comma-separated list of JSON field
+// names; a "-" prefix sorts in descending order, a "+" prefix (or none) in
+// ascending order, and a ":nulls_first" or ":nulls_last" suffix adds NULLS
+// FIRST or NULLS LAST. Fields stored in JSONB columns are supported. An error
+// wrapping ErrInvalidSort is returned if a field is not permitted.
+//
+//	order, err := users.Permit("Name", "CreatedAt").OrderBy("-created_at,name")
+//	if err != nil {
+//		return err
+//	}
+//	users.Find().OrderBy(order).MustQuery(&list)
+//	// SELECT ... ORDER BY created_at DESC, name ASC
+func (m ModelWithPermittedFields) OrderBy(sort string) (string, error) {
+	var expressions []string
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		direction := "ASC"
+		if strings.HasPrefix(part, "-") {
+			direction = "DESC"
+			part = part[1:]
+		} else if strings.HasPrefix(part, "+") {
+			part = part[1:]
+		}
+		var nulls string
+		if idx := strings.Index(part, ":"); idx > -1 {
+			switch strings.ToLower(part[idx+1:]) {
+			case "nulls_first":
+				nulls = " NULLS FIRST"
+			case "nulls_last":
+				nulls = " NULLS LAST"
+			default:
+				return "", fmt.Errorf("%w: unknown option %q", ErrInvalidSort, part[idx+1:])
+			}
+			part = part[:idx]
+		}
+		field := m.permittedFieldByJsonName(strings.TrimSpace(part))
+		if field == nil {
+			return "", fmt.Errorf("%w: field %q is not permitted", ErrInvalidSort, part)
+		}
+		expressions = append(expressions, field.expr()+" "+direction+nulls)
+	}
+	return strings.Join(expressions, ", "), nil
+}
+
+func (m ModelWithPermittedFields) permittedFieldByJsonName(name string) *Field {
+	if name == "" {
+		return nil
+	}
+	for _, i := range m.permittedFieldsIdx {
+		field := m.modelFields[i]
+		if field.JsonName == name {
+			return &field
+		}
+	}
+	return nil
+}
+
+// Query converts query string values into WHERE conditions of a new SELECT
+// query, so that list endpoints can be filtered from user input safely. Keys
+// are JSON field names of permitted fields, optionally followed by an
+// operator in brackets:
+//
+//	| Key              | Condition                     |
+//	|------------------|-------------------------------|
+//	| name=foo         | name = $1                     |
+//	| age[ne]=30       | age <> $1                     |
+//	| age[gt]=30       | age > $1 (also gte, lt, lte)  |
+//	| status[in]=a,b   | status IN ($1, $2)            |
+//	| status[nin]=a,b  | status NOT IN ($1, $2)        |
+//	| name[like]=foo%  | name LIKE $1 (also ilike)     |
+//	| deleted[null]=1  | deleted IS NULL (0: NOT NULL) |
+//
+// Values are converted to the Go type of each field like Filter does, and
+// fields stored in JSONB columns are supported. Keys without brackets that
+// are not permitted fields (e.g. "page" or "sort") are ignored. All other
+// invalid parameters are returned together as an InvalidParamsError.
+//
+//	sql, err := users.Permit("Name", "Age").Query(c.QueryParams())
+//	if err != nil {
+//		return err
+//	}
+//	sql.Find().OrderBy("id").MustQuery(&list)
+func (m ModelWithPermittedFields) Query(values url.Values) (*SelectSQL, error) {
+	s := m.newSelect()
+	var invalid InvalidParamsError
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name, op := key, "eq"
+		hasOp := false
+		if idx := strings.Index(key, "["); idx > -1 && strings.HasSuffix(key, "]") {
+			name, op = key[:idx], key[idx+1:len(key)-1]
+			hasOp = true
+		}
+		field := m.permittedFieldByJsonName(name)
+		for _, value := range values[key] {
+			if field == nil {
+				if hasOp {
+					invalid = append(invalid, InvalidParam{key, value, "field is not permitted"})
+				}
+				continue
+			}
+			if err := m.addQueryCondition(s, *field, op, value); err != nil {
+				invalid = append(invalid, InvalidParam{key, value, err.Error()})
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, invalid
+	}
+	return s, nil
+}
+
+func (m ModelWithPermittedFields) addQueryCondition(s *SelectSQL, field Field, op, value string) error {
+	operator, ok := queryOperators[op]
+	if !ok {
+		return fmt.Errorf("unknown operator %q", op)
+	}
+	if m.structType == nil {
+		return errors.New("model has no struct type")
+	}
+	f, ok := m.structType.FieldByName(field.Name)
+	if !ok {
+		return errors.New("field not found")
+	}
+	switch op {
+	case "null":
+		null, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("invalid value for bool")
+		}
+		if !null {
+			operator = "IS NOT NULL"
+		}
+		s.conditions = append(s.conditions, field.expr()+" "+operator)
+	case "in", "nin":
+		var numbers []string
+		var args []interface{}
+		for _, v := range strings.Split(value, ",") {
+			arg, err := convertQueryValue(f.Type, v)
+			if err != nil {
+				return err
+			}
+			args = append(args, arg)
+			numbers = append(numbers, fmt.Sprintf("$%d", len(s.args)+len(args)))
+		}
+		s.args = append(s.args, args...)
+		s.conditions = append(s.conditions, field.expr()+" "+operator+" ("+strings.Join(numbers, ", ")+")")
+	case "like", "ilike":
+		if jsonbCastType(field.ColumnType) != "text" {
+			return errors.New("operator " + op + " requires a string field")
+		}
+		s.args = append(s.args, value)
+		s.conditions = append(s.conditions, fmt.Sprintf("%s %s $%d", field.expr(), operator, len(s.args)))
+	default:
+		arg, err := convertQueryValue(f.Type, value)
+		if err != nil {
+			return err
+		}
+		condition, arg, err := m.tupleCondition(field.Name, operator, arg, len(s.args)+1)
+		if err != nil {
+			return err
+		}
+		s.args = append(s.args, arg)
+		s.conditions = append(s.conditions, condition)
+	}
+	return nil
+}
+
+// convertQueryValue converts a query string value to the given type through
+// JSON, first as a JSON string (strings, time.Time) and then as a raw JSON
+// value (numbers, booleans).
+func convertQueryValue(rt reflect.Type, value string) (interface{}, error) {
+	x := reflect.New(rt)
+	v, _ := json.Marshal(value)
+	if json.Unmarshal(v, x.Interface()) == nil {
+		return x.Elem().Interface(), nil
+	}
+	x = reflect.New(rt)
+	if err := json.Unmarshal([]byte(value), x.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid value for %s", rt)
+	}
+	return x.Elem().Interface(), nil
+}
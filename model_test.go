@@ -1,6 +1,7 @@
 package psql
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"testing"
@@ -503,3 +504,366 @@ func TestModelDropSchema(t *testing.T) {
 		t.Errorf("DropSchema() = %q, want %q", got, want)
 	}
 }
+
+func TestModelFieldExpr(t *testing.T) {
+	t.Parallel()
+
+	type fieldExprStruct struct {
+		Id        int
+		Name      string
+		FullName  string    `jsonb:"meta"`
+		Age       *int32    `jsonb:"meta"`
+		Score     float64   `jsonb:"meta"`
+		Verified  bool      `jsonb:"meta"`
+		BirthDate time.Time `jsonb:"meta"`
+		Tags      []string  `jsonb:"meta"`
+	}
+
+	m := NewModel(fieldExprStruct{})
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Id", "id"},
+		{"Name", "name"},
+		{"FullName", "meta->>'full_name'"},
+		{"Age", "(meta->>'age')::integer"},
+		{"Score", "(meta->>'score')::numeric"},
+		{"Verified", "(meta->>'verified')::boolean"},
+		{"BirthDate", "(meta->>'birth_date')::timestamptz"},
+		{"Tags", "meta->'tags'"},
+		{"COUNT(*)", "COUNT(*)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := m.FieldExpr(tt.field); got != tt.want {
+				t.Errorf("FieldExpr(%q) = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelCopyFromSource(t *testing.T) {
+	t.Parallel()
+
+	type copyStruct struct {
+		Id      int
+		Name    string
+		Picture string `jsonb:"meta"`
+	}
+
+	m := NewModel(copyStruct{})
+
+	ch := make(chan *copyStruct, 3)
+	ch <- &copyStruct{Name: "a", Picture: "a.jpg"}
+	ch <- nil
+	ch <- &copyStruct{Name: "b"}
+	close(ch)
+	i := 0
+	sources := map[string]interface{}{
+		"slice":   []copyStruct{{Name: "a", Picture: "a.jpg"}, {Name: "b"}},
+		"channel": ch,
+		"iterator": func() (copyStruct, bool) {
+			i++
+			switch i {
+			case 1:
+				return copyStruct{Name: "a", Picture: "a.jpg"}, true
+			case 2:
+				return copyStruct{Name: "b"}, true
+			}
+			return copyStruct{}, false
+		},
+	}
+	want := [][]interface{}{
+		{"a", `{"picture":"a.jpg"}`},
+		{"b", `{"picture":""}`},
+	}
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			src, err := m.copySource(source, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(src.columns(), ","); got != "name,meta" {
+				t.Errorf("columns = %q, want %q", got, "name,meta")
+			}
+			var got [][]interface{}
+			for src.Next() {
+				values, err := src.Values()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, values)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("values = %v, want %v", got, want)
+			}
+		})
+	}
+
+	for _, source := range []interface{}{nil, copyStruct{}, []string{"a"}, func() bool { return false }} {
+		if _, err := m.copyIterator(source); err != ErrInvalidCopySource {
+			t.Errorf("copyIterator(%T) error = %v, want ErrInvalidCopySource", source, err)
+		}
+	}
+
+	columnTests := []struct {
+		name    string
+		source  interface{}
+		fields  []string
+		want    string
+		wantErr bool
+	}{
+		{"zero ids", []copyStruct{{Name: "a"}, {Name: "b"}}, nil, "name,meta", false},
+		{"explicit ids", []*copyStruct{nil, {Id: 3, Name: "a"}}, nil, "id,name,meta", false},
+		{"ids in channel", ch, nil, "name,meta", false},
+		{"fields", []copyStruct{}, []string{"Picture", "Id"}, "id,meta", false},
+		{"unknown field", []copyStruct{}, []string{"Foo"}, "", true},
+	}
+	for _, tt := range columnTests {
+		src, err := m.copySource(tt.source, tt.fields)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: copySource() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil {
+			if got := strings.Join(src.columns(), ","); got != tt.want {
+				t.Errorf("%s: columns = %q, want %q", tt.name, got, tt.want)
+			}
+		}
+	}
+
+	src, err := m.copySource([]copyStruct{{Id: 3, Name: "a"}, {Name: "b"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Next()
+	if values, err := src.Values(); err != nil || !reflect.DeepEqual(values, []interface{}{3, "a", `{"picture":""}`}) {
+		t.Errorf("values = %v, %v", values, err)
+	}
+	src.Next()
+	if _, err := src.Values(); err == nil || src.Err() != err {
+		t.Errorf("Values() error = %v, want error of zero id", err)
+	}
+}
+
+type (
+	// copyTestDB records rows copied with the CopyFromer interface.
+	copyTestDB struct {
+		*dryRunDB
+		table   string
+		columns []string
+		rows    [][]interface{}
+	}
+
+	// pgxCopyTestDB records rows copied with a CopyFrom method of the shape
+	// of pgx.
+	pgxCopyTestDB struct {
+		copyTestDB
+	}
+
+	pgxCopyTestIdentifier []string
+
+	pgxCopyTestSource interface {
+		Next() bool
+		Values() ([]interface{}, error)
+		Err() error
+	}
+)
+
+func (d *copyTestDB) CopyFrom(ctx context.Context, tableName string, columns []string, source CopyFromSource) (int64, error) {
+	d.table, d.columns = tableName, columns
+	for source.Next() {
+		values, err := source.Values()
+		if err != nil {
+			return int64(len(d.rows)), err
+		}
+		d.rows = append(d.rows, values)
+	}
+	return int64(len(d.rows)), source.Err()
+}
+
+func (d *pgxCopyTestDB) CopyFrom(ctx context.Context, tableName pgxCopyTestIdentifier, columns []string, source pgxCopyTestSource) (int64, error) {
+	return d.copyTestDB.CopyFrom(ctx, strings.Join(tableName, "."), columns, source)
+}
+
+func TestModelCopyFrom(t *testing.T) {
+	t.Parallel()
+
+	type copyStruct struct {
+		Id      int
+		Name    string
+		Picture string `jsonb:"meta"`
+	}
+
+	rows := []copyStruct{{Id: 1, Name: "a", Picture: "a.jpg"}, {Id: 2, Name: "b"}}
+	want := [][]interface{}{
+		{1, "a", `{"picture":"a.jpg"}`},
+		{2, "b", `{"picture":""}`},
+	}
+
+	copyDB := &copyTestDB{dryRunDB: &dryRunDB{}}
+	pgxDB := &pgxCopyTestDB{copyTestDB{dryRunDB: &dryRunDB{}}}
+	for name, db := range map[string]*copyTestDB{"CopyFromer": copyDB, "pgx": &pgxDB.copyTestDB} {
+		t.Run(name, func(t *testing.T) {
+			var conn interface{} = db
+			if name == "pgx" {
+				conn = pgxDB
+			}
+			m := NewModel(copyStruct{}, conn)
+			n, err := m.CopyFrom(context.Background(), nil, rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != 2 {
+				t.Errorf("n = %d, want 2", n)
+			}
+			if db.table != "copy_structs" || strings.Join(db.columns, ",") != "id,name,meta" {
+				t.Errorf("copied into %s (%v)", db.table, db.columns)
+			}
+			if !reflect.DeepEqual(db.rows, want) {
+				t.Errorf("rows = %v, want %v", db.rows, want)
+			}
+			if stmts := m.Statements(); len(stmts) != 0 {
+				t.Errorf("statements = %v, want none", stmts)
+			}
+		})
+	}
+
+	t.Run("INSERT fallback", func(t *testing.T) {
+		m := NewModel(copyStruct{}).DryRun()
+		n, err := m.CopyFrom(context.Background(), nil, rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("n = %d, want 0 (dry run)", n)
+		}
+		want := []string{"INSERT INTO copy_structs (id, name, meta) VALUES ($1, $2, $3), ($4, $5, $6)"}
+		var got []string
+		for _, stmt := range m.Statements() {
+			got = append(got, stmt.SQL)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("statements = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestModelDryRun(t *testing.T) {
+	t.Parallel()
+
+	type dryRunStruct struct {
+		Id          int
+		Name        string
+		LockVersion int `column:",version"`
+	}
+
+	m := NewModel(dryRunStruct{})
+	dry := m.DryRun()
+	if m.isDryRun() || !dry.isDryRun() {
+		t.Fatal("DryRun should return a dry run copy of the model")
+	}
+
+	var id int
+	dry.Insert("Name", "a").Returning("id").MustQueryRow(&id)
+	dry.Update("Name", "b", "LockVersion", 1).Where("id = $1", 1).MustExecute()
+	var rows []dryRunStruct
+	dry.Find().Where("name = $1", "b").MustQuery(&rows)
+	if dry.MustCount() != 0 || dry.MustExists() {
+		t.Error("dry run queries should return no rows")
+	}
+	dry.MustTransaction(func(ctx context.Context, tx Tx) error {
+		dry.Delete().Where("id = $1", 1).MustExecuteExpect(ctx, tx, 1)
+		return nil
+	})
+
+	want := []Statement{
+		{"INSERT INTO dry_run_structs (name) VALUES ($1) RETURNING id", []interface{}{"a"}},
+		{"UPDATE dry_run_structs SET name = $2, lock_version = lock_version + 1 WHERE (id = $1) AND (lock_version = $3)", []interface{}{1, "b", 1}},
+		{"SELECT id, name, lock_version FROM dry_run_structs WHERE name = $1", []interface{}{"b"}},
+		{"SELECT COUNT(*) FROM dry_run_structs", nil},
+		{"SELECT 1 AS one FROM dry_run_structs", nil},
+		{"DELETE FROM dry_run_structs WHERE id = $1", []interface{}{1}},
+	}
+	got := dry.Statements()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Statements() = %v, want %v", got, want)
+	}
+	if id != 0 || rows != nil {
+		t.Errorf("targets should be untouched, got %v and %v", id, rows)
+	}
+	if len(dry.Clone().Statements()) != len(want) {
+		t.Error("clones should share the statements")
+	}
+	if m.Statements() != nil {
+		t.Error("Statements() of a regular model should be nil")
+	}
+
+	for query, want := range map[string]bool{
+		"EXPLAIN SELECT 1":                       true,
+		"EXPLAIN (COSTS false) SELECT 1":         true,
+		"EXPLAIN (ANALYZE, BUFFERS) DELETE FROM": false,
+		"EXPLAIN ANALYZE DELETE FROM users":      false,
+		"explain verbose analyze select 1":       false,
+		"SELECT 1":                               false,
+	} {
+		if got := isExplain(query); got != want {
+			t.Errorf("isExplain(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestModelDryRunInTransaction(t *testing.T) {
+	t.Parallel()
+
+	type dryRunStruct struct {
+		Id   int
+		Name string
+	}
+
+	conn := &affectedTestDB{dryRunDB: &dryRunDB{}, affected: 1}
+	m := NewModel(dryRunStruct{}, conn)
+	dry := m.DryRun()
+	err := m.TransactionCtx(context.Background(), func(ctx context.Context, tx Tx) error {
+		if err := dry.Update("Name", "a").Where("id = $1", 1).ExecuteCtx(ctx); err != nil {
+			return err
+		}
+		if err := dry.Delete().Where("id = $1", 2).ExecuteCtxTx(ctx, tx); err != nil {
+			return err
+		}
+		var rows []dryRunStruct
+		if err := dry.Find().QueryCtxTx(ctx, tx, &rows); err != nil {
+			return err
+		}
+		if err := dry.TransactionCtxTx(ctx, tx, func(ctx context.Context, tx Tx) error {
+			return dry.Insert("Name", "b").ExecuteCtx(ctx)
+		}); err != nil {
+			return err
+		}
+		_, err := dry.CopyFrom(ctx, tx, []dryRunStruct{{Name: "c"}}, "Name")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"UPDATE dry_run_structs SET name = $2 WHERE id = $1",
+		"DELETE FROM dry_run_structs WHERE id = $1",
+		"SELECT id, name FROM dry_run_structs",
+		"INSERT INTO dry_run_structs (name) VALUES ($1)",
+		"INSERT INTO dry_run_structs (name) VALUES ($1)",
+	}
+	var got []string
+	for _, stmt := range dry.Statements() {
+		got = append(got, stmt.SQL)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+	if stmts := conn.statements; len(stmts) != 0 {
+		t.Errorf("statements of the transaction = %v, want none", stmts)
+	}
+}
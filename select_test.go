@@ -13,6 +13,14 @@ type selectTestStruct struct {
 	CreatedAt string
 }
 
+// Test struct with JSONB for SELECT tests
+type selectJsonbStruct struct {
+	Id      int
+	Price   int      `jsonb:"meta"`
+	Picture string   `jsonb:"meta"`
+	Tags    []string `jsonb:"meta"`
+}
+
 func TestSelect(t *testing.T) {
 	t.Parallel()
 	m := NewModel(selectTestStruct{})
@@ -95,6 +103,109 @@ func TestSelectFind(t *testing.T) {
 	}
 }
 
+func TestSelectFindOnlyExcept(t *testing.T) {
+	t.Parallel()
+
+	type onlyTestStruct struct {
+		Id       int
+		Name     string
+		Password string
+		Theme    string `jsonb:"meta"`
+		Locale   string `jsonb:"meta"`
+		Note     string `jsonb:"extra"`
+	}
+
+	m := NewModel(onlyTestStruct{})
+
+	tests := []struct {
+		name       string
+		build      func() *SelectSQL
+		wantSQL    string
+		wantFields []string
+	}{
+		{
+			name:       "only regular fields",
+			build:      func() *SelectSQL { return m.Find(Only("Id", "Name")) },
+			wantSQL:    "SELECT id, name FROM only_test_structs",
+			wantFields: []string{"Id", "Name"},
+		},
+		{
+			name:       "only jsonb field by column",
+			build:      func() *SelectSQL { return m.Find(Only("Id", "Meta.Theme")) },
+			wantSQL:    "SELECT id, meta FROM only_test_structs",
+			wantFields: []string{"Id", "Theme"},
+		},
+		{
+			name:       "only jsonb field by name",
+			build:      func() *SelectSQL { return m.Find(Only("Note")) },
+			wantSQL:    "SELECT extra FROM only_test_structs",
+			wantFields: []string{"Note"},
+		},
+		{
+			name:       "only whole jsonb column",
+			build:      func() *SelectSQL { return m.Find(Only("Meta")) },
+			wantSQL:    "SELECT meta FROM only_test_structs",
+			wantFields: []string{"Theme", "Locale"},
+		},
+		{
+			name:       "except",
+			build:      func() *SelectSQL { return m.Find(Except("Password", "Extra")) },
+			wantSQL:    "SELECT id, name, meta FROM only_test_structs",
+			wantFields: []string{"Id", "Name", "Theme", "Locale"},
+		},
+		{
+			name:       "with table name",
+			build:      func() *SelectSQL { return m.Find(Only("Id"), AddTableName) },
+			wantSQL:    "SELECT only_test_structs.id FROM only_test_structs",
+			wantFields: []string{"Id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := tt.build()
+			if got := sql.String(); got != tt.wantSQL {
+				t.Errorf("String() = %q, want %q", got, tt.wantSQL)
+			}
+			var fields []string
+			for _, f := range sql.selection.modelFields {
+				fields = append(fields, f.Name)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("scanned fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+
+	t.Run("model is not changed", func(t *testing.T) {
+		m.Find(Only("Id"))
+		if got := len(m.modelFields); got != 6 {
+			t.Errorf("len(modelFields) = %d, want 6", got)
+		}
+	})
+
+	t.Run("other fields in the rest of the query", func(t *testing.T) {
+		got, args := m.Find(Only("Name")).WHERE("Theme", "=", "dark").StringValues()
+		want := "SELECT name FROM only_test_structs WHERE meta->>'theme' = $1"
+		if got != want || !reflect.DeepEqual(args, []interface{}{"dark"}) {
+			t.Errorf("StringValues() = %q, %v, want %q, [dark]", got, args, want)
+		}
+		got = m.Find(Except("Password")).Where("id = $1", 1).Update("Password", "x").String()
+		want = "UPDATE only_test_structs SET password = $2 WHERE id = $1"
+		if got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		for _, sql := range []*SelectSQL{m.Find(Only("Nope")), m.Find(Except("Id", "Meta.Nope"))} {
+			if err := sql.guard(); err == nil || err.Error() != `unknown field "Nope"` && err.Error() != `unknown field "Meta.Nope"` {
+				t.Errorf("guard() error = %v, want unknown field", err)
+			}
+		}
+	})
+}
+
 func TestSelectWhere(t *testing.T) {
 	t.Parallel()
 	m := NewModel(selectTestStruct{})
@@ -201,6 +312,94 @@ func TestSelectWHERE(t *testing.T) {
 	}
 }
 
+func TestSelectWHEREJsonb(t *testing.T) {
+	t.Parallel()
+	m := NewModel(selectJsonbStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *SelectSQL
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "text field",
+			build:    func() *SelectSQL { return m.Select("id").WHERE("Picture", "=", "a.jpg") },
+			wantSQL:  "SELECT id FROM select_jsonb_structs WHERE meta->>'picture' = $1",
+			wantArgs: []interface{}{"a.jpg"},
+		},
+		{
+			name:     "field with cast",
+			build:    func() *SelectSQL { return m.Select("id").WHERE("Price", ">", 10) },
+			wantSQL:  "SELECT id FROM select_jsonb_structs WHERE (meta->>'price')::bigint > $1",
+			wantArgs: []interface{}{10},
+		},
+		{
+			name:     "containment",
+			build:    func() *SelectSQL { return m.Select("id").WHERE("Tags", "@>", []string{"a"}) },
+			wantSQL:  "SELECT id FROM select_jsonb_structs WHERE meta->'tags' @> $1::jsonb",
+			wantArgs: []interface{}{`["a"]`},
+		},
+		{
+			name:     "key exists",
+			build:    func() *SelectSQL { return m.Select("id").WHERE("Tags", "?", "a") },
+			wantSQL:  "SELECT id FROM select_jsonb_structs WHERE meta->'tags' ? $1",
+			wantArgs: []interface{}{"a"},
+		},
+		{
+			name:     "any key exists",
+			build:    func() *SelectSQL { return m.Select("id").WHERE("Tags", "?|", []string{"a", "b"}) },
+			wantSQL:  "SELECT id FROM select_jsonb_structs WHERE meta->'tags' ?| $1::text[]",
+			wantArgs: []interface{}{`{"a","b"}`},
+		},
+		{
+			name:     "all keys exist",
+			build:    func() *SelectSQL { return m.Select("id").WHERE("Tags", "?&", []string{"a"}) },
+			wantSQL:  "SELECT id FROM select_jsonb_structs WHERE meta->'tags' ?& $1::text[]",
+			wantArgs: []interface{}{`{"a"}`},
+		},
+		{
+			name:     "jsonb column containment",
+			build:    func() *SelectSQL { return m.Select("id").WHERE("Meta", "@>", map[string]int{"price": 1}) },
+			wantSQL:  "SELECT id FROM select_jsonb_structs WHERE meta @> $1::jsonb",
+			wantArgs: []interface{}{`{"price":1}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := tt.build()
+			gotSQL, gotArgs := sql.StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSelectWHEREJsonbError(t *testing.T) {
+	t.Parallel()
+	m := NewModel(selectJsonbStruct{})
+
+	sql := m.Select("id").WHERE("Tags", "@>", func() {})
+	if err := sql.guard(); err == nil {
+		t.Error("guard() error = nil, want JSON encoding error")
+	}
+	if err := sql.Query(&[]int{}); err != ErrNoConnection {
+		t.Errorf("Query() error = %v, want %v", err, ErrNoConnection)
+	}
+	if err := m.DryRun().Select("id").WHERE("Tags", "@>", func() {}).Query(&[]int{}); err == nil {
+		t.Error("Query() error = nil, want JSON encoding error")
+	}
+	sql = m.Select("id").WHERE("Tags", "?|", 1)
+	if err := sql.guard(); err == nil {
+		t.Error("guard() error = nil, want text array error")
+	}
+}
+
 func TestSelectOrderBy(t *testing.T) {
 	t.Parallel()
 	m := NewModel(selectTestStruct{})
@@ -220,6 +419,18 @@ func TestSelectOrderBy(t *testing.T) {
 			build:   func() *SelectSQL { return m.Select("id").OrderBy("name ASC", "id DESC") },
 			wantSQL: "SELECT id FROM select_test_structs ORDER BY name ASC, id DESC",
 		},
+		{
+			name:    "struct field names",
+			build:   func() *SelectSQL { return m.Select("id").OrderBy("CreatedAt DESC", "Name") },
+			wantSQL: "SELECT id FROM select_test_structs ORDER BY created_at DESC, name",
+		},
+		{
+			name: "jsonb fields",
+			build: func() *SelectSQL {
+				return NewModel(selectJsonbStruct{}).Select("id").OrderBy("Price DESC NULLS LAST", "Picture")
+			},
+			wantSQL: "SELECT id FROM select_jsonb_structs ORDER BY (meta->>'price')::bigint DESC NULLS LAST, meta->>'picture'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -612,3 +823,150 @@ func TestSelectAsSelect(t *testing.T) {
 		t.Errorf("String() = %q, want %q", got, want)
 	}
 }
+
+func TestSelectAggregate(t *testing.T) {
+	t.Parallel()
+	m := NewModel(selectJsonbStruct{})
+
+	t.Run("does not mutate select list", func(t *testing.T) {
+		sql := m.Find().Where("id > $1", 1)
+		want := sql.String()
+		var total int
+		if err := sql.Sum("Price", &total); err != ErrNoConnection {
+			t.Errorf("Sum() error = %v, want %v", err, ErrNoConnection)
+		}
+		if _, err := sql.Count(); err != ErrNoConnection {
+			t.Errorf("Count() error = %v, want %v", err, ErrNoConnection)
+		}
+		var tags [][]string
+		if err := sql.Pluck("Tags", &tags); err != ErrNoConnection {
+			t.Errorf("Pluck() error = %v, want %v", err, ErrNoConnection)
+		}
+		if got := sql.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("dest must be pointer", func(t *testing.T) {
+		var total int
+		if err := m.Find().Max("Price", total); err != ErrMustBePointer {
+			t.Errorf("Max() error = %v, want %v", err, ErrMustBePointer)
+		}
+	})
+
+	t.Run("null scans to zero", func(t *testing.T) {
+		dry := m.DryRun()
+		total := 5
+		if err := dry.Find().Sum("Price", &total); err != nil {
+			t.Fatal(err)
+		}
+		if total != 0 {
+			t.Errorf("total = %d, want 0", total)
+		}
+		max := new(int)
+		if err := dry.Find().Max("Price", &max); err != nil {
+			t.Fatal(err)
+		}
+		if max == nil {
+			t.Error("max = nil, want pointer left untouched")
+		}
+	})
+
+	tests := []struct {
+		name    string
+		call    func(*SelectSQL) error
+		wantSQL string
+	}{
+		{
+			name: "sum column",
+			call: func(s *SelectSQL) error {
+				var total int
+				return s.Sum("Id", &total)
+			},
+			wantSQL: "SELECT SUM(id) FROM select_jsonb_structs WHERE id > $1",
+		},
+		{
+			name: "sum jsonb field with cast",
+			call: func(s *SelectSQL) error {
+				var total int
+				return s.Sum("Price", &total)
+			},
+			wantSQL: "SELECT SUM((meta->>'price')::bigint) FROM select_jsonb_structs WHERE id > $1",
+		},
+		{
+			name: "sum expression",
+			call: func(s *SelectSQL) error {
+				var total int
+				return s.Sum("id * 2", &total)
+			},
+			wantSQL: "SELECT SUM(id * 2) FROM select_jsonb_structs WHERE id > $1",
+		},
+		{
+			name: "avg jsonb field",
+			call: func(s *SelectSQL) error {
+				var avg float64
+				return s.Avg("Price", &avg)
+			},
+			wantSQL: "SELECT AVG((meta->>'price')::bigint) FROM select_jsonb_structs WHERE id > $1",
+		},
+		{
+			name: "min column",
+			call: func(s *SelectSQL) error {
+				var min int
+				return s.Min("Id", &min)
+			},
+			wantSQL: "SELECT MIN(id) FROM select_jsonb_structs WHERE id > $1",
+		},
+		{
+			name: "max text jsonb field",
+			call: func(s *SelectSQL) error {
+				var max string
+				return s.Max("Picture", &max)
+			},
+			wantSQL: "SELECT MAX(meta->>'picture') FROM select_jsonb_structs WHERE id > $1",
+		},
+		{
+			name: "pluck column",
+			call: func(s *SelectSQL) error {
+				var ids []int
+				return s.Pluck("Id", &ids)
+			},
+			wantSQL: "SELECT id FROM select_jsonb_structs WHERE id > $1",
+		},
+		{
+			name: "pluck jsonb field with cast",
+			call: func(s *SelectSQL) error {
+				var prices []int
+				return s.Pluck("Price", &prices)
+			},
+			wantSQL: "SELECT (meta->>'price')::bigint FROM select_jsonb_structs WHERE id > $1",
+		},
+		{
+			name: "pluck jsonb array",
+			call: func(s *SelectSQL) error {
+				var tags [][]string
+				return s.Pluck("Tags", &tags)
+			},
+			wantSQL: "SELECT COALESCE(meta->'tags', 'null')::text FROM select_jsonb_structs WHERE id > $1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dry := m.DryRun()
+			if err := tt.call(dry.Find().Where("id > $1", 1)); err != nil {
+				t.Fatal(err)
+			}
+			stmts := dry.Statements()
+			if len(stmts) != 1 {
+				t.Fatalf("got %d statements, want 1", len(stmts))
+			}
+			if stmts[0].SQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", stmts[0].SQL, tt.wantSQL)
+			}
+			if want := []interface{}{1}; !reflect.DeepEqual(stmts[0].Args, want) {
+				t.Errorf("Args = %v, want %v", stmts[0].Args, want)
+			}
+		})
+	}
+}
@@ -25,9 +25,30 @@ var (
 	// a database connection set on the Model.
 	ErrNoConnection = errors.New("no connection")
 
+	// ErrNoConditions is returned when an UPDATE or DELETE statement built
+	// without any WHERE conditions is executed, unless AllowAll is called.
+	ErrNoConditions = errors.New("refusing to update or delete all rows without conditions")
+
+	// ErrNoConflictTarget is returned when an INSERT statement with an
+	// OnConflictWhere predicate has no conflict target columns.
+	ErrNoConflictTarget = errors.New("OnConflictWhere requires conflict target columns")
+
+	// ErrNoDoUpdate is returned when an INSERT statement with a Where
+	// condition has no ON CONFLICT DO UPDATE action.
+	ErrNoDoUpdate = errors.New("Where of an INSERT statement requires an ON CONFLICT DO UPDATE action")
+
 	// ErrNoSQL is returned when Execute is called with an empty SQL statement.
 	ErrNoSQL = errors.New("no sql statements to execute")
 
+	// ErrStaleObject is returned when Execute of an UPDATE with optimistic
+	// locking affects no rows, because the row has been updated (or deleted)
+	// by someone else since its version was read.
+	ErrStaleObject = errors.New("stale object")
+
+	// ErrTooManyRows is returned when Query returns more rows than the
+	// maximum set by Model.SetMaxQueryRows.
+	ErrTooManyRows = errors.New("too many rows")
+
 	// ErrTypeAssertionFailed is returned when scanning a JSONB value fails
 	// due to an unexpected source type.
 	ErrTypeAssertionFailed = errors.New("type assertion failed")
@@ -36,6 +57,15 @@ var (
 	// target type that is not supported (*string, io.Writer, logger.Logger,
 	// func(string), or func(...interface{})).
 	ErrUnsupportedExplainTarget = errors.New("unsupported explain target type")
+
+	// ErrUnsupportedMaxAffected is returned by Query and QueryRow of a
+	// statement with MaxAffected, which is only supported by Execute.
+	ErrUnsupportedMaxAffected = errors.New("MaxAffected is only supported by Execute")
+
+	// ErrUnexpectedRowsAffected is returned by ExecuteExpect, or Execute of a
+	// statement with MaxAffected, if the statement affects a different number
+	// of rows than expected. The changes of the statement are rolled back.
+	ErrUnexpectedRowsAffected = errors.New("unexpected number of rows affected")
 )
 
 type (
@@ -53,6 +83,15 @@ type (
 		values         []interface{}
 		explainTarget  interface{}
 		explainOptions []string
+		expect         *rowsExpectation
+		err            error
+	}
+
+	// rowsExpectation is the number of rows a statement is expected to
+	// affect, or the maximum number if max is true.
+	rowsExpectation struct {
+		n   int64
+		max bool
 	}
 
 	// Tx is an alias for db.Tx, representing a database transaction.
@@ -180,6 +219,8 @@ func (s SQL) MustQueryCtx(ctx context.Context, target interface{}) {
 }
 
 // QueryCtx is like Query but accepts a context for cancellation and timeouts.
+// The query executes within the transaction of the context, if any (see
+// Model.TransactionCtx).
 func (s SQL) QueryCtx(ctx context.Context, target interface{}) error {
 	return s.QueryCtxTx(ctx, nil, target)
 }
@@ -197,14 +238,20 @@ func (s SQL) QueryCtxTx(ctx context.Context, tx Tx, target interface{}) error {
 	if s.model.connection == nil {
 		return ErrNoConnection
 	}
-
-	sqlQuery, values := s.StringValues()
-	if sqlQuery == "" {
-		return nil
+	if err := s.guard(); err != nil {
+		return err
+	}
+	if s.expect != nil {
+		return ErrUnsupportedMaxAffected
+	}
+	if tx == nil {
+		tx = txFromContext(ctx)
 	}
+	tx = s.model.ownTx(tx)
 
-	if err := s.runExplain(ctx, tx, sqlQuery, values); err != nil {
-		return err
+	stmts := s.statements()
+	if len(stmts) == 1 && stmts[0].sql == "" {
+		return nil
 	}
 
 	var rv reflect.Value
@@ -241,12 +288,17 @@ func (s SQL) QueryCtxTx(ctx context.Context, tx Tx, target interface{}) error {
 	kind := rt.Kind()
 	if kind == reflect.Slice {
 		rt = rt.Elem()
+	} else if kind != reflect.Struct && kind != reflect.Map {
+		return ErrInvalidTarget
 	}
 
 	var mi *modelInfo
 	if s.model.structType != nil && rt == s.model.structType {
 		// use model's existing info if type is the same
 		mi = s.model.modelInfo
+		if f, ok := s.main.(fieldSelection); ok && f.selectedFields() != nil {
+			mi = f.selectedFields()
+		}
 	} else {
 		// different type of struct
 		mi = &modelInfo{tableName: s.model.tableName}
@@ -254,64 +306,84 @@ func (s SQL) QueryCtxTx(ctx context.Context, tx Tx, target interface{}) error {
 		mi.updateColumnNames(rt)
 	}
 
-	if kind == reflect.Struct { // if target is not a slice, use QueryRow instead
-		start := time.Now()
-		defer s.log(sqlQuery, values, start)
-		if tx != nil {
-			return mi.scan(rv, tx.QueryRowContext(ctx, sqlQuery, values...))
+	count := 0 // rows read by all statements, see Model.SetMaxQueryRows
+	return s.inBatches(ctx, tx, stmts, func(ctx context.Context, tx Tx, stmt statement) error {
+		sqlQuery, values := stmt.sql, stmt.values
+		if err := s.runExplain(ctx, tx, sqlQuery, values); err != nil {
+			return err
 		}
-		return mi.scan(rv, s.model.connection.QueryRowContext(ctx, sqlQuery, values...))
-	} else if kind == reflect.Map {
-		start := time.Now()
-		defer s.log(sqlQuery, values, start)
-		var rows db.Rows
-		var err error
-		if tx != nil {
-			rows, err = tx.QueryContext(ctx, sqlQuery, values...)
-		} else {
-			rows, err = s.model.connection.QueryContext(ctx, sqlQuery, values...)
+		switch kind {
+		case reflect.Struct: // if target is not a slice, use QueryRow instead
+			return s.queryStruct(ctx, tx, mi, rv, sqlQuery, values)
+		case reflect.Map:
+			return s.queryMap(ctx, tx, rv, rt, sqlQuery, values, &count)
 		}
-		if err != nil {
+		return s.querySlice(ctx, tx, mi, rv, rt, sqlQuery, values, &count)
+	})
+}
+
+func (s SQL) queryStruct(ctx context.Context, tx Tx, mi *modelInfo, rv reflect.Value, sqlQuery string, values []interface{}) error {
+	start := time.Now()
+	defer s.log(sqlQuery, values, start)
+	if tx != nil {
+		return mi.scan(rv, tx.QueryRowContext(ctx, sqlQuery, values...))
+	}
+	return mi.scan(rv, s.model.connection.QueryRowContext(ctx, sqlQuery, values...))
+}
+
+func (s SQL) queryMap(ctx context.Context, tx Tx, rv reflect.Value, rt reflect.Type, sqlQuery string, values []interface{}, count *int) error {
+	start := time.Now()
+	defer s.log(sqlQuery, values, start)
+	var rows db.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, sqlQuery, values...)
+	} else {
+		rows, err = s.model.connection.QueryContext(ctx, sqlQuery, values...)
+	}
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+	columns, _ := rows.Columns()
+	columnLen := len(columns)
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(rt, 0))
+	}
+	mapKeyType, mapValueType := rt.Key(), rt.Elem()
+	isSlice := mapValueType.Kind() == reflect.Slice
+	valueTypes := mapValueTypes(rt)
+	for rows.Next() {
+		if err := s.model.checkMaxQueryRows(count); err != nil {
 			return err
 		}
-
-		defer rows.Close()
-		columns, _ := rows.Columns()
-		columnLen := len(columns)
-		if rv.IsNil() {
-			rv.Set(reflect.MakeMapWithSize(rt, 0))
+		mapKeys, end, dests := newDestsForMapType(mapKeyType, mapValueType, columnLen)
+		if err := rows.Scan(dests...); err != nil {
+			return err
 		}
-		mapKeyType, mapValueType := rt.Key(), rt.Elem()
-		isSlice := mapValueType.Kind() == reflect.Slice
-		valueTypes := mapValueTypes(rt)
-		for rows.Next() {
-			mapKeys, end, dests := newDestsForMapType(mapKeyType, mapValueType, columnLen)
-			if err := rows.Scan(dests...); err != nil {
-				return err
-			}
-			if isSlice {
-				slice := rv.MapIndex(mapKeys[0])
-				if !slice.IsValid() {
-					slice = reflect.MakeSlice(valueTypes[0], 0, 0)
-				}
-				rv.SetMapIndex(mapKeys[0], reflect.Append(slice, end))
-				continue
+		if isSlice {
+			slice := rv.MapIndex(mapKeys[0])
+			if !slice.IsValid() {
+				slice = reflect.MakeSlice(valueTypes[0], 0, 0)
 			}
-			subMap := rv
-			i := 0
-			for ; i < len(mapKeys)-1; i++ { // map[type]map...
-				if !subMap.MapIndex(mapKeys[i]).IsValid() {
-					subMap.SetMapIndex(mapKeys[i], reflect.MakeMap(valueTypes[i]))
-				}
-				subMap = subMap.MapIndex(mapKeys[i])
+			rv.SetMapIndex(mapKeys[0], reflect.Append(slice, end))
+			continue
+		}
+		subMap := rv
+		i := 0
+		for ; i < len(mapKeys)-1; i++ { // map[type]map...
+			if !subMap.MapIndex(mapKeys[i]).IsValid() {
+				subMap.SetMapIndex(mapKeys[i], reflect.MakeMap(valueTypes[i]))
 			}
-			subMap.SetMapIndex(mapKeys[i], end)
+			subMap = subMap.MapIndex(mapKeys[i])
 		}
-		return rows.Err()
-	} else if kind != reflect.Slice {
-		return ErrInvalidTarget
+		subMap.SetMapIndex(mapKeys[i], end)
 	}
+	return rows.Err()
+}
 
+func (s SQL) querySlice(ctx context.Context, tx Tx, mi *modelInfo, rv reflect.Value, rt reflect.Type, sqlQuery string, values []interface{}, count *int) error {
 	start := time.Now()
 	defer s.log(sqlQuery, values, start)
 	var rows db.Rows
@@ -326,6 +398,9 @@ func (s SQL) QueryCtxTx(ctx context.Context, tx Tx, target interface{}) error {
 	}
 	defer rows.Close()
 	for rows.Next() {
+		if err := s.model.checkMaxQueryRows(count); err != nil {
+			return err
+		}
 		nv := reflect.New(rt).Elem()
 		if err := mi.scan(nv, rows); err != nil {
 			return err
@@ -408,7 +483,8 @@ func (s SQL) MustQueryRowCtx(ctx context.Context, dest ...interface{}) {
 }
 
 // QueryRowCtx is like QueryRow but accepts a context for cancellation and
-// timeouts.
+// timeouts. The query executes within the transaction of the context, if any
+// (see Model.TransactionCtx).
 func (s SQL) QueryRowCtx(ctx context.Context, dest ...interface{}) error {
 	return s.QueryRowCtxTx(ctx, nil, dest...)
 }
@@ -427,19 +503,60 @@ func (s SQL) QueryRowCtxTx(ctx context.Context, tx Tx, dest ...interface{}) erro
 	if s.model.connection == nil {
 		return ErrNoConnection
 	}
-	sqlQuery, values := s.StringValues()
-	if sqlQuery == "" {
-		return nil
-	}
-	if err := s.runExplain(ctx, tx, sqlQuery, values); err != nil {
+	if err := s.guard(); err != nil {
 		return err
 	}
-	start := time.Now()
-	defer s.log(sqlQuery, values, start)
-	if tx != nil {
-		return tx.QueryRowContext(ctx, sqlQuery, values...).Scan(dest...)
+	if s.expect != nil {
+		return ErrUnsupportedMaxAffected
+	}
+	if tx == nil {
+		tx = txFromContext(ctx)
+	}
+	tx = s.model.ownTx(tx)
+	stmts := s.statements()
+	if len(stmts) == 1 && stmts[0].sql == "" {
+		return nil
+	}
+	return s.inBatches(ctx, tx, stmts, func(ctx context.Context, tx Tx, stmt statement) error {
+		sqlQuery, values := stmt.sql, stmt.values
+		if err := s.runExplain(ctx, tx, sqlQuery, values); err != nil {
+			return err
+		}
+		start := time.Now()
+		defer s.log(sqlQuery, values, start)
+		if tx != nil {
+			return tx.QueryRowContext(ctx, sqlQuery, values...).Scan(dest...)
+		}
+		return s.model.connection.QueryRowContext(ctx, sqlQuery, values...).Scan(dest...)
+	})
+}
+
+// MaxAffected makes Execute fail with ErrUnexpectedRowsAffected and roll back
+// the changes of the statement if it affects more than n rows. See
+// ExecuteExpect. Query and QueryRow do not count the rows affected, so they
+// return ErrUnsupportedMaxAffected.
+func (s *SQL) MaxAffected(n int64) *SQL {
+	s.expect = &rowsExpectation{n: n, max: true}
+	return s
+}
+
+// MustExecuteExpect is like ExecuteExpect but panics if execute operation
+// fails.
+func (s SQL) MustExecuteExpect(ctx context.Context, tx Tx, n int64, dest ...interface{}) {
+	if err := s.ExecuteExpect(ctx, tx, n, dest...); err != nil {
+		panic(err)
 	}
-	return s.model.connection.QueryRowContext(ctx, sqlQuery, values...).Scan(dest...)
+}
+
+// ExecuteExpect is like ExecuteCtxTx but expects the statement to affect
+// exactly n rows. The statement is executed in a savepoint of tx (or in a new
+// transaction if tx is nil), which is rolled back and ErrUnexpectedRowsAffected
+// is returned if the number of rows affected differs.
+//
+//	err := users.Update("Plan", "pro").Where("id = ANY($1)", ids).ExecuteExpect(ctx, nil, int64(len(ids)))
+func (s SQL) ExecuteExpect(ctx context.Context, tx Tx, n int64, dest ...interface{}) error {
+	s.expect = &rowsExpectation{n: n}
+	return s.ExecuteCtxTx(ctx, tx, dest...)
 }
 
 // MustExecute is like Execute but panics if execute operation fails.
@@ -463,7 +580,8 @@ func (s SQL) MustExecuteCtx(ctx context.Context, dest ...interface{}) {
 }
 
 // ExecuteCtx is like Execute but accepts a context for cancellation and
-// timeouts.
+// timeouts. The statement executes within the transaction of the context, if
+// any (see Model.TransactionCtx).
 func (s SQL) ExecuteCtx(ctx context.Context, dest ...interface{}) error {
 	return s.ExecuteCtxTx(ctx, nil, dest...)
 }
@@ -481,19 +599,199 @@ func (s SQL) ExecuteCtxTx(ctx context.Context, tx Tx, dest ...interface{}) error
 	if s.model.connection == nil {
 		return ErrNoConnection
 	}
-	sqlQuery, values := s.StringValues()
-	if sqlQuery == "" {
+	if err := s.guard(); err != nil {
+		return err
+	}
+	if tx == nil {
+		tx = txFromContext(ctx)
+	}
+	tx = s.model.ownTx(tx)
+	if s.expect != nil && !s.model.isDryRun() {
+		return s.executeExpected(ctx, tx, dest...)
+	}
+	stmts := s.statements()
+	if len(stmts) == 1 && stmts[0].sql == "" {
 		return ErrNoSQL
 	}
-	if err := s.runExplain(ctx, tx, sqlQuery, values); err != nil {
+	checkVersion := false
+	if v, ok := s.main.(versionChecker); ok && !s.model.isDryRun() {
+		checkVersion = v.checksVersion()
+	}
+	versioned := false
+	for _, stmt := range stmts {
+		versioned = versioned || stmt.rows > 0 && !s.model.isDryRun()
+	}
+	count := len(dest) > 0 || checkVersion || versioned
+	var rowsAffected int64
+	exec := func(ctx context.Context, tx Tx) error {
+		return s.inBatches(ctx, tx, stmts, func(ctx context.Context, tx Tx, stmt statement) error {
+			sqlQuery, values := stmt.sql, stmt.values
+			if err := s.runExplain(ctx, tx, sqlQuery, values); err != nil {
+				return err
+			}
+			start := time.Now()
+			defer s.log(sqlQuery, values, start)
+			var ra int64
+			var err error
+			if tx != nil {
+				err = returnRowsAffected(count, &ra)(tx.ExecContext(ctx, sqlQuery, values...))
+			} else {
+				err = returnRowsAffected(count, &ra)(s.model.connection.ExecContext(ctx, sqlQuery, values...))
+			}
+			rowsAffected += ra
+			if err == nil && versioned && ra < stmt.rows {
+				return ErrStaleObject
+			}
+			return err
+		})
+	}
+	var err error
+	if versioned {
+		// rows with stale versions are not updated, so the statements are
+		// executed in a savepoint, which is rolled back if any row is stale
+		err = s.model.TransactionCtxTx(ctx, tx, exec)
+	} else {
+		err = exec(ctx, tx)
+	}
+	if err != nil {
 		return err
 	}
-	start := time.Now()
-	defer s.log(sqlQuery, values, start)
-	if tx != nil {
-		return returnRowsAffected(dest)(tx.ExecContext(ctx, sqlQuery, values...))
+	if len(dest) > 0 {
+		switch x := dest[0].(type) {
+		case *int:
+			*x = int(rowsAffected)
+		case *int64:
+			*x = rowsAffected
+		}
+	}
+	if checkVersion && rowsAffected == 0 {
+		return ErrStaleObject
+	}
+	return nil
+}
+
+// executeExpected executes the statement in a savepoint of tx (or in a new
+// transaction if tx is nil), which is rolled back if the number of rows
+// affected is not as expected.
+func (s SQL) executeExpected(ctx context.Context, tx Tx, dest ...interface{}) error {
+	expect := *s.expect
+	s.expect = nil
+	var rowsAffected int64
+	run := func(ctx context.Context, tx Tx) error {
+		if err := s.ExecuteCtxTx(ctx, tx, &rowsAffected); err != nil {
+			return err
+		}
+		if expect.max && rowsAffected > expect.n {
+			return fmt.Errorf("%w: %d rows affected, expected at most %d", ErrUnexpectedRowsAffected, rowsAffected, expect.n)
+		}
+		if !expect.max && rowsAffected != expect.n {
+			return fmt.Errorf("%w: %d rows affected, expected %d", ErrUnexpectedRowsAffected, rowsAffected, expect.n)
+		}
+		return nil
+	}
+	if err := s.model.TransactionCtxTx(ctx, tx, run); err != nil {
+		return err
+	}
+	if len(dest) > 0 {
+		switch x := dest[0].(type) {
+		case *int:
+			*x = int(rowsAffected)
+		case *int64:
+			*x = rowsAffected
+		}
+	}
+	return nil
+}
+
+// guard returns the error of a builder method of s, if any, or
+// ErrNoConditions if s is an UPDATE or DELETE statement without conditions
+// which has not been allowed to change all rows.
+func (s SQL) guard() error {
+	if s.err != nil {
+		return s.err
+	}
+	if g, ok := s.main.(conditionsGuard); ok && g.missingConditions() {
+		return ErrNoConditions
 	}
-	return returnRowsAffected(dest)(s.model.connection.ExecContext(ctx, sqlQuery, values...))
+	if v, ok := s.main.(validator); ok {
+		return v.validate()
+	}
+	return nil
+}
+
+type (
+	// statement is a single SQL statement with its parameter values.
+	statement struct {
+		sql    string
+		values []interface{}
+		rows   int64 // rows that must be affected, or the versions are stale
+	}
+
+	// batcher is implemented by statement builders that may render more than
+	// one statement, such as a multi-row INSERT that exceeds the parameter
+	// limit of PostgreSQL.
+	batcher interface {
+		batches() []statement
+	}
+
+	// conditionsGuard is implemented by UPDATE and DELETE statement builders,
+	// which refuse to change all rows of a table unless AllowAll is called.
+	conditionsGuard interface {
+		missingConditions() bool
+	}
+
+	// validator is implemented by statement builders that check their
+	// options before the statement is executed.
+	validator interface {
+		validate() error
+	}
+
+	// fieldSelection is implemented by queries that select a subset of the
+	// fields of the model, which are scanned instead of all fields.
+	fieldSelection interface {
+		selectedFields() *modelInfo
+	}
+
+	// versionChecker is implemented by statement builders that check the
+	// version of optimistic locking, so that no rows affected is an error.
+	versionChecker interface {
+		checksVersion() bool
+	}
+)
+
+// setErr records the error of a builder method, which is returned when the
+// statement is executed. Only the first error is kept.
+func (s *SQL) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// statements returns the statements to execute for s.
+func (s SQL) statements() []statement {
+	if b, ok := s.main.(batcher); ok {
+		if stmts := b.batches(); len(stmts) > 0 {
+			return stmts
+		}
+	}
+	sqlQuery, values := s.StringValues()
+	return []statement{{sql: sqlQuery, values: values}}
+}
+
+// inBatches calls run for each statement. Multiple statements are executed in
+// a transaction unless tx is already given.
+func (s SQL) inBatches(ctx context.Context, tx Tx, stmts []statement, run func(context.Context, Tx, statement) error) error {
+	if len(stmts) == 1 || tx != nil {
+		for _, stmt := range stmts {
+			if err := run(ctx, tx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return s.model.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+		return s.inBatches(ctx, tx, stmts, run)
+	})
 }
 
 func (s SQL) log(sql string, args []interface{}, startTime time.Time) {
@@ -524,6 +822,12 @@ func (s SQL) runExplain(ctx context.Context, tx Tx, sqlQuery string, values []in
 	if err != nil {
 		return err
 	}
+	return writeExplain(s.explainTarget, rows)
+}
+
+// writeExplain reads the query plan from the rows of an EXPLAIN statement and
+// writes it to target.
+func writeExplain(target interface{}, rows db.Rows) (err error) {
 	defer rows.Close()
 
 	var lines []string
@@ -539,7 +843,7 @@ func (s SQL) runExplain(ctx context.Context, tx Tx, sqlQuery string, values []in
 	}
 
 	result := strings.Join(lines, "\n")
-	switch t := s.explainTarget.(type) {
+	switch t := target.(type) {
 	case *string:
 		*t = result
 	case io.Writer:
@@ -557,25 +861,18 @@ func (s SQL) runExplain(ctx context.Context, tx Tx, sqlQuery string, values []in
 	return nil
 }
 
-func returnRowsAffected(dest []interface{}) func(db.Result, error) error {
+// returnRowsAffected stores the number of rows affected into ra if count is
+// true.
+func returnRowsAffected(count bool, ra *int64) func(db.Result, error) error {
 	return func(result db.Result, err error) error {
 		if err != nil {
 			return err
 		}
-		if len(dest) == 0 {
+		if !count {
 			return nil
 		}
-		ra, err := result.RowsAffected()
-		if err != nil {
-			return err
-		}
-		switch x := dest[0].(type) {
-		case *int:
-			*x = int(ra)
-		case *int64:
-			*x = ra
-		}
-		return nil
+		*ra, err = result.RowsAffected()
+		return err
 	}
 }
 
@@ -13,6 +13,9 @@ type (
 		sqlConditions
 		usingList        string
 		outputExpression string
+		scope            string
+		hard             bool
+		allowAll         bool
 	}
 )
 
@@ -26,6 +29,8 @@ func (s SQL) AsDelete() *DeleteSQL {
 }
 
 // Delete creates a DELETE statement. Use Where to specify which rows to delete.
+// Executing a DELETE without conditions returns ErrNoConditions, unless
+// AllowAll is called.
 //
 //	// Delete with condition
 //	users.Delete().Where("id = $1", 1).MustExecute()
@@ -33,10 +38,46 @@ func (s SQL) AsDelete() *DeleteSQL {
 //	// Delete with RETURNING clause
 //	var ids []int
 //	users.Delete().Where("status = $1", "inactive").Returning("id").MustQuery(&ids)
+//
+// If the model has a soft delete column (a nullable timestamp field tagged
+// with `column:",softdelete"`), rows are not deleted but marked as deleted by
+// setting the column to the current time. Queries of the model (Find, Select,
+// Count, Exists, etc.) then skip deleted rows, unless Unscoped or OnlyDeleted
+// is used. Call HardDelete to actually delete rows.
+//
+//	type User struct {
+//		Id        int
+//		Name      string
+//		DeletedAt *time.Time `column:",softdelete"`
+//	}
+//	users.Delete().Where("id = $1", 1).MustExecute()
+//	// UPDATE users SET deleted_at = NOW() WHERE (id = $1) AND (deleted_at IS NULL)
 func (m Model) Delete() *DeleteSQL {
 	return m.NewSQL("").AsDelete()
 }
 
+// Restore creates an UPDATE statement that clears the soft delete column of
+// deleted rows (see Delete). Use Where to specify which rows to restore.
+//
+//	users.Restore().Where("id = $1", 1).MustExecute()
+//	// UPDATE users SET deleted_at = NULL WHERE (id = $1) AND (deleted_at IS NOT NULL)
+func (m Model) Restore() *UpdateSQL {
+	field, ok := m.softDeleteField()
+	if !ok {
+		return m.Update()
+	}
+	u := m.Update(field.Name, Remove)
+	u.scope = field.ColumnName + " IS NOT NULL"
+	return u
+}
+
+// HardDelete makes the statement delete rows of a model with a soft delete
+// column, instead of marking them as deleted.
+func (s *DeleteSQL) HardDelete() *DeleteSQL {
+	s.hard = true
+	return s
+}
+
 // Where adds a WHERE condition to the DELETE statement. Use $1, $2 for
 // positional parameters, or $? which is auto-replaced when a single argument
 // is provided.
@@ -64,12 +105,37 @@ func (s *DeleteSQL) WHERE(args ...interface{}) *DeleteSQL {
 		if column == "" || operator == "" {
 			continue
 		}
-		s.args = append(s.args, args[i*3+2])
-		s.conditions = append(s.conditions, fmt.Sprintf("%s %s $%d", s.model.ToColumnName(column), operator, len(s.args)))
+		condition, arg, err := s.model.tupleCondition(column, operator, args[i*3+2], len(s.args)+1)
+		if err != nil {
+			s.setErr(err)
+			continue
+		}
+		s.args = append(s.args, arg)
+		s.conditions = append(s.conditions, condition)
 	}
 	return s
 }
 
+// AllowAll allows the statement to delete all rows of the table when it has
+// no WHERE conditions.
+//
+//	users.Delete().AllowAll().MustExecute()
+func (s *DeleteSQL) AllowAll() *DeleteSQL {
+	s.allowAll = true
+	return s
+}
+
+// MaxAffected makes Execute fail with ErrUnexpectedRowsAffected and roll back
+// the deletion if it affects more than n rows.
+func (s *DeleteSQL) MaxAffected(n int64) *DeleteSQL {
+	s.SQL.MaxAffected(n)
+	return s
+}
+
+func (s *DeleteSQL) missingConditions() bool {
+	return s.sql == "" && len(s.conditions) == 0 && !s.allowAll
+}
+
 // Using adds a USING clause for DELETE with joins.
 func (s *DeleteSQL) Using(list ...string) *DeleteSQL {
 	s.usingList = strings.Join(list, ", ")
@@ -82,6 +148,16 @@ func (s *DeleteSQL) Returning(expressions ...string) *DeleteSQL {
 	return s
 }
 
+// ReturningModel adds a RETURNING clause of all columns of the Model (see
+// Columns), so that the deleted rows can be scanned into the Model's struct
+// (or a slice of it) with Query, like the results of Find.
+//
+//	var deleted []User
+//	users.Delete().Where("status = $1", "inactive").ReturningModel().MustQuery(&deleted)
+func (s *DeleteSQL) ReturningModel() *DeleteSQL {
+	return s.Returning(s.model.AddTableName(s.model.Columns()...)...)
+}
+
 // Tap applies transformation functions to this DeleteSQL, enabling custom
 // method chaining.
 func (s *DeleteSQL) Tap(funcs ...func(*DeleteSQL) *DeleteSQL) *DeleteSQL {
@@ -112,16 +188,32 @@ func (s *DeleteSQL) ExplainAnalyze(target interface{}, options ...string) *Delet
 
 func (s *DeleteSQL) String() string {
 	var sql string
+	conditions := s.sqlConditions
+	field, soft := s.model.softDeleteField()
+	soft = soft && !s.hard && s.sql == ""
 	if s.sql != "" {
 		sql = s.formattedSQL()
+	} else if soft {
+		sql = "UPDATE " + s.model.tableName + " SET " + field.ColumnName + " = NOW()"
+		column := field.ColumnName
+		if s.usingList != "" {
+			column = s.model.tableName + "." + column
+		}
+		conditions.conditions = append(conditions.conditions[:len(conditions.conditions):len(conditions.conditions)],
+			column+" IS NULL")
 	} else {
 		sql = "DELETE FROM " + s.model.tableName
 	}
+	if s.scope != "" && s.sql == "" && !(soft && s.scope == field.ColumnName+" IS NULL") {
+		conditions.conditions = append(conditions.conditions[:len(conditions.conditions):len(conditions.conditions)], s.scope)
+	}
 	if sql != "" {
-		if s.usingList != "" {
+		if s.usingList != "" && soft {
+			sql += " FROM " + s.usingList
+		} else if s.usingList != "" {
 			sql += " USING " + s.usingList
 		}
-		sql += s.where()
+		sql += conditions.where()
 		if s.outputExpression != "" {
 			sql += " RETURNING " + s.outputExpression
 		}
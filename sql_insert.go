@@ -1,8 +1,10 @@
 package psql
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -11,15 +13,25 @@ type (
 	// Model.Insert or SQL.AsInsert.
 	InsertSQL struct {
 		*SQL
-		changes          []interface{}
-		outputExpression string
-		conflictTargets  []string
-		conflictActions  []string
-		updateAll        bool
-		updateAllExcept  []string
+		sqlConditions
+		changes            []interface{}
+		columns            []string
+		query              *SelectSQL
+		outputExpression   string
+		conflictTargets    []string
+		conflictConstraint string
+		conflictWhere      string
+		conflictActions    []string
+		updateAll          bool
+		updateAllExcept    []string
+		mergeJSONB         []string
 	}
 )
 
+// maxParameters is the maximum number of parameters PostgreSQL accepts in a
+// single statement.
+const maxParameters = 65535
+
 // AsInsert converts a raw SQL statement to an InsertSQL builder with the given
 // changes.
 func (s SQL) AsInsert(changes ...interface{}) *InsertSQL {
@@ -41,23 +53,209 @@ func (s SQL) AsInsert(changes ...interface{}) *InsertSQL {
 //	// Using Changes from Filter
 //	changes := users.Permit("Name", "Email").Filter(input)
 //	users.Insert(changes).Returning("id").MustQueryRow(&id)
+//
+// To insert multiple rows with one statement, pass a []Changes or a slice of
+// the model's struct (or pointers to it). Other changes are applied to every
+// row. Columns missing from a row are set to DEFAULT; zero-valued struct
+// fields of SERIAL or GENERATED columns (such as the id) are left to the
+// database as well. Rows exceeding the parameter limit of
+// PostgreSQL are split into several statements, which are executed in a
+// transaction.
+//
+//	// Insert multiple rows
+//	users.Insert([]User{{Name: "Alice"}, {Name: "Bob"}}).MustExecute()
+//
+//	// Insert multiple rows with common changes
+//	users.Insert(rows, users.CreatedAt()).Returning("id").MustQuery(&ids)
 func (m Model) Insert(lotsOfChanges ...interface{}) *InsertSQL {
 	return m.NewSQL("").AsInsert(lotsOfChanges...)
 }
 
+// MustInsertStruct is like InsertStruct but panics if insert operation fails.
+func (m Model) MustInsertStruct(ctx context.Context, tx Tx, target interface{}) {
+	if err := m.InsertStruct(ctx, tx, target); err != nil {
+		panic(err)
+	}
+}
+
+// InsertStruct inserts every field of the struct that target points to, and
+// scans the inserted row back into it, so that the generated id, default
+// values and columns populated by triggers are set. Zero-valued fields that
+// have a default value in the database (such as a SERIAL id or DEFAULT NOW())
+// are left to the database. Tx can be nil.
+//
+//	user := User{Name: "Alice"}
+//	users.MustInsertStruct(ctx, nil, &user)
+//	// INSERT INTO users (name) VALUES ($1) RETURNING id, name, created_at
+//	fmt.Println(user.Id, user.CreatedAt)
+func (m Model) InsertStruct(ctx context.Context, tx Tx, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrMustBePointer
+	}
+	if !m.isStructType(rv.Type()) {
+		return ErrInvalidTarget
+	}
+	return m.insertStruct(rv).QueryCtxTx(ctx, tx, target)
+}
+
+// insertStruct returns the INSERT statement of InsertStruct.
+func (m Model) insertStruct(rv reflect.Value) *InsertSQL {
+	changes := m.structChanges(rv, m.ColumnDataTypes(), hasDatabaseDefault)
+	if len(changes) == 0 {
+		return m.NewSQL("INSERT INTO " + m.tableName + " DEFAULT VALUES").AsInsert().Returning(m.Columns()...)
+	}
+	return m.Insert([]Changes{changes}).Returning(m.Columns()...)
+}
+
+// InsertFrom creates an INSERT ... SELECT statement inserting the rows
+// returned by query. Fields are struct field names (or column names) matching
+// the select list of query in order. Select list items of fields stored in
+// the same JSONB column are combined into one jsonb_build_object expression,
+// so fields stored in JSONB columns require a query built with an explicit
+// select list (like Select) of as many items as fields; otherwise an error is
+// returned when the statement is executed. The arguments of query are merged
+// into the statement.
+//
+//	archived.InsertFrom([]string{"Id", "Name", "Picture"},
+//		users.Select("id", "name", "picture").Where("deleted_at < $1", t),
+//	).OnConflict("id").DoNothing().MustExecute()
+//	// INSERT INTO archived_users (id, name, meta)
+//	// SELECT id, name, jsonb_build_object('picture', picture) FROM users
+//	// WHERE deleted_at < $1 ON CONFLICT (id) DO NOTHING
+func (m Model) InsertFrom(fields []string, query *SelectSQL) *InsertSQL {
+	i := m.NewSQL("").AsInsert()
+	var exprs []string
+	if query.sql == "" && len(query.fields) == len(fields) {
+		exprs = query.fields
+	}
+	var selectList []string
+	jsonbIndex := map[string]int{}
+	jsonbPairs := map[string][]string{}
+	for idx, name := range fields {
+		var field *Field
+		for _, f := range m.modelFields {
+			if f.Name == name {
+				field = &f
+				break
+			}
+		}
+		if field != nil && field.Jsonb != "" && exprs == nil {
+			i.setErr(fmt.Errorf("field %s is stored in JSONB column %s, which requires a select list of %d items", name, field.Jsonb, len(fields)))
+			continue
+		}
+		if field == nil || field.Jsonb == "" {
+			column := name
+			if field != nil {
+				column = field.ColumnName
+			}
+			i.columns = append(i.columns, column)
+			if exprs != nil {
+				selectList = append(selectList, exprs[idx])
+			}
+			continue
+		}
+		if _, ok := jsonbIndex[field.Jsonb]; !ok {
+			jsonbIndex[field.Jsonb] = len(selectList)
+			i.columns = append(i.columns, field.Jsonb)
+			selectList = append(selectList, "")
+		}
+		jsonbPairs[field.Jsonb] = append(jsonbPairs[field.Jsonb], "'"+field.ColumnName+"', "+exprs[idx])
+	}
+	for column, idx := range jsonbIndex {
+		selectList[idx] = "jsonb_build_object(" + strings.Join(jsonbPairs[column], ", ") + ")"
+	}
+	if len(jsonbIndex) > 0 {
+		i.query = query.selectOnly(selectList...)
+	} else {
+		i.query = query
+	}
+	return i
+}
+
 // Returning adds a RETURNING clause to retrieve values from inserted rows.
 func (s *InsertSQL) Returning(expressions ...string) *InsertSQL {
 	s.outputExpression = strings.Join(expressions, ", ")
 	return s
 }
 
+// ReturningModel adds a RETURNING clause of all columns of the Model (see
+// Columns), so that the inserted rows can be scanned into the Model's struct
+// (or a slice of it) with Query, like the results of Find.
+//
+//	var user User
+//	users.Insert("Name", "Alice").ReturningModel().MustQuery(&user)
+func (s *InsertSQL) ReturningModel() *InsertSQL {
+	return s.Returning(s.model.AddTableName(s.model.Columns()...)...)
+}
+
 // OnConflict specifies conflict target columns for upsert operations. Use with
 // DoNothing, DoUpdate, or DoUpdateAll.
 func (s *InsertSQL) OnConflict(targets ...string) *InsertSQL {
 	s.conflictTargets = append([]string{}, targets...)
+	s.conflictConstraint = ""
 	return s
 }
 
+// OnConflictConstraint specifies the name of the unique or exclusion
+// constraint as the conflict target for upsert operations. Use with
+// DoNothing, DoUpdate, or DoUpdateAll.
+//
+//	users.Insert(changes).OnConflictConstraint("users_email_key").DoNothing()
+//	// INSERT INTO users (...) VALUES (...) ON CONFLICT ON CONSTRAINT users_email_key DO NOTHING
+func (s *InsertSQL) OnConflictConstraint(name string) *InsertSQL {
+	s.conflictTargets = []string{}
+	s.conflictConstraint = name
+	return s
+}
+
+// OnConflictWhere adds a predicate to the conflict target, so that a partial
+// unique index can be inferred. Must be used after OnConflict with target
+// columns; otherwise ErrNoConflictTarget is returned when the statement is
+// executed, since a constraint name can not have a predicate.
+//
+//	users.Insert(changes).OnConflict("email").OnConflictWhere("deleted_at IS NULL").DoNothing()
+//	// INSERT INTO users (...) VALUES (...) ON CONFLICT (email) WHERE deleted_at IS NULL DO NOTHING
+func (s *InsertSQL) OnConflictWhere(predicate string) *InsertSQL {
+	s.conflictWhere = predicate
+	return s
+}
+
+// Where adds a condition to the DO UPDATE action of an upsert, so that
+// conflicting rows are only updated if the condition is met. Use $1, $2 for
+// positional parameters, or $? which is auto-replaced when a single argument
+// is provided; parameters are numbered after the inserted values. The
+// condition does not apply to the insertion itself: executing the statement
+// without a DO UPDATE action (see DoUpdate, DoUpdateAll and DoUpdateAllExcept)
+// returns ErrNoDoUpdate.
+//
+//	users.Insert(changes).OnConflict("id").DoUpdateAll().
+//		Where("users.updated_at < EXCLUDED.updated_at")
+func (s *InsertSQL) Where(condition string, args ...interface{}) *InsertSQL {
+	s.args = append(s.args, args...)
+	if len(args) == 1 {
+		condition = strings.Replace(condition, "$?", fmt.Sprintf("$%d", len(s.args)), -1)
+	}
+	s.conditions = append(s.conditions, condition)
+	return s
+}
+
+// validate returns ErrNoConflictTarget if OnConflictWhere is used without
+// target columns, and ErrNoDoUpdate if Where is used without a DO UPDATE
+// action, which the predicate or condition would be silently dropped from.
+func (s *InsertSQL) validate() error {
+	if s.conflictWhere != "" && (len(s.conflictTargets) == 0 || s.conflictConstraint != "") {
+		return ErrNoConflictTarget
+	}
+	if len(s.conditions) == 0 {
+		return nil
+	}
+	if s.conflictTargets == nil || !s.updateAll && len(s.updateAllExcept) == 0 && len(s.conflictActions) == 0 {
+		return ErrNoDoUpdate
+	}
+	return nil
+}
+
 // DoNothing adds ON CONFLICT DO NOTHING, ignoring rows that conflict. Must be
 // used after OnConflict.
 func (s *InsertSQL) DoNothing() *InsertSQL {
@@ -87,6 +285,22 @@ func (s *InsertSQL) DoUpdateAllExcept(fields ...string) *InsertSQL {
 	return s
 }
 
+// MergeJSONB makes DoUpdateAll and DoUpdateAllExcept merge the given JSONB
+// columns (or all JSONB columns of the model if none is given) into the
+// existing values instead of overwriting them, so that keys not present in
+// the insert are kept.
+//
+//	users.Insert(changes).OnConflict("id").DoUpdateAll().MergeJSONB("meta")
+//	// ... ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name,
+//	// meta = COALESCE(users.meta, '{}'::jsonb) || EXCLUDED.meta
+func (s *InsertSQL) MergeJSONB(columns ...string) *InsertSQL {
+	if len(columns) == 0 {
+		columns = s.model.jsonbColumns
+	}
+	s.mergeJSONB = append(s.mergeJSONB, columns...)
+	return s
+}
+
 // Tap applies transformation functions to this InsertSQL, enabling custom
 // method chaining.
 func (s *InsertSQL) Tap(funcs ...func(*InsertSQL) *InsertSQL) *InsertSQL {
@@ -121,6 +335,15 @@ func (s InsertSQL) String() string {
 }
 
 func (s *InsertSQL) StringValues() (string, []interface{}) {
+	if s.query != nil {
+		sql := "INSERT INTO " + s.model.tableName + " (" + strings.Join(s.columns, ", ") + ") " + s.query.String()
+		values := append([]interface{}{}, s.query.args...)
+		suffix, args := s.onConflictReturning(s.columns, len(values))
+		return s.model.convertValues(sql+suffix, append(values, args...))
+	}
+	if rows := s.getRows(); rows != nil {
+		return s.rowsStringValues(rows)
+	}
 	fields := []string{}
 	fieldsIndex := map[string]int{}
 	numbers := []string{}
@@ -136,6 +359,9 @@ func (s *InsertSQL) StringValues() (string, []interface{}) {
 				jsonbFields[field.Jsonb][field] = value
 				continue
 			}
+			if _, ok := value.(remove); ok {
+				continue
+			}
 			if idx, ok := fieldsIndex[field.Name]; ok { // prevent duplication
 				values[idx] = value
 				continue
@@ -150,11 +376,7 @@ func (s *InsertSQL) StringValues() (string, []interface{}) {
 	for jsonbField, changes := range jsonbFields {
 		fields = append(fields, jsonbField)
 		numbers = append(numbers, fmt.Sprintf("$%d", i))
-		out := map[string]interface{}{}
-		for field, value := range changes {
-			out[field.ColumnName] = value
-		}
-		j, _ := json.Marshal(out)
+		j, _ := json.Marshal(jsonbObject(changes))
 		values = append(values, string(j))
 		i += 1
 	}
@@ -170,50 +392,260 @@ func (s *InsertSQL) StringValues() (string, []interface{}) {
 		}
 	}
 	if sql != "" {
-		if s.conflictTargets != nil {
-			var actions []string
-			if s.updateAll {
-				for _, field := range fields {
-					actions = append(actions, field+" = EXCLUDED."+field)
-				}
-			} else if len(s.updateAllExcept) > 0 {
-			outer:
-				for _, field := range fields {
-					for _, except := range s.updateAllExcept {
-						if field == except {
-							continue outer
-						}
+		suffix, args := s.onConflictReturning(fields, len(values))
+		sql += suffix
+		values = append(values, args...)
+	}
+	return s.model.convertValues(sql, values)
+}
+
+// onConflictReturning renders the ON CONFLICT and RETURNING clauses for an
+// INSERT of the given columns and offset parameters, and returns the
+// parameter values of the clauses.
+func (s *InsertSQL) onConflictReturning(fields []string, offset int) (sql string, args []interface{}) {
+	if s.conflictTargets != nil {
+		var actions []string
+		if s.updateAll {
+			for _, field := range fields {
+				actions = append(actions, s.updateExcluded(field))
+			}
+		} else if len(s.updateAllExcept) > 0 {
+		outer:
+			for _, field := range fields {
+				for _, except := range s.updateAllExcept {
+					if field == except {
+						continue outer
 					}
-					actions = append(actions, field+" = EXCLUDED."+field)
 				}
+				actions = append(actions, s.updateExcluded(field))
 			}
-			if s.conflictActions != nil {
-				if actions == nil {
-					actions = []string{}
-				}
-				actions = append(actions, s.conflictActions...)
-			}
-			if actions != nil {
-				action := strings.Join(actions, ", ")
-				if action == "" {
-					action = "DO NOTHING"
-				} else {
-					action = "DO UPDATE SET " + action
-				}
-				target := strings.Join(s.conflictTargets, ", ")
-				if target != "" && !strings.HasPrefix(target, "(") {
-					target = "(" + target + ")"
-				}
-				if target == "" {
-					sql += " ON CONFLICT " + action
-				} else {
-					sql += " ON CONFLICT " + target + " " + action
+		}
+		if s.conflictActions != nil {
+			if actions == nil {
+				actions = []string{}
+			}
+			actions = append(actions, s.conflictActions...)
+		}
+		if actions != nil {
+			action := strings.Join(actions, ", ")
+			if action == "" {
+				action = "DO NOTHING"
+			} else {
+				action = "DO UPDATE SET " + action
+				if len(s.conditions) > 0 {
+					action += shiftParameters(s.where(), offset)
+					args = s.args
 				}
 			}
+			target := strings.Join(s.conflictTargets, ", ")
+			if target != "" && !strings.HasPrefix(target, "(") {
+				target = "(" + target + ")"
+			}
+			if s.conflictConstraint != "" {
+				target = "ON CONSTRAINT " + s.conflictConstraint
+			} else if target != "" && s.conflictWhere != "" {
+				target += " WHERE " + s.conflictWhere
+			}
+			if target == "" {
+				sql += " ON CONFLICT " + action
+			} else {
+				sql += " ON CONFLICT " + target + " " + action
+			}
 		}
-		if s.outputExpression != "" {
-			sql += " RETURNING " + s.outputExpression
+	}
+	if s.outputExpression != "" {
+		sql += " RETURNING " + s.outputExpression
+	}
+	return
+}
+
+// updateExcluded returns the DO UPDATE SET action of a column to the value
+// proposed for insertion.
+func (s *InsertSQL) updateExcluded(column string) string {
+	for _, merge := range s.mergeJSONB {
+		if merge == column {
+			return fmt.Sprintf("%s = COALESCE(%s.%s, '{}'::jsonb) || EXCLUDED.%s", column, s.model.tableName, column, column)
 		}
 	}
-	return s.model.convertValues(sql, values)
+	return column + " = EXCLUDED." + column
+}
+
+// getRows returns the rows to insert if a []Changes or a slice of structs is
+// given, each merged with the other changes, or nil for a single row INSERT.
+func (s *InsertSQL) getRows() []Changes {
+	var common []interface{}
+	var rows []Changes
+	var dataTypes map[string]string
+	multi := false
+	expectKey := true
+	for _, item := range s.changes {
+		if !expectKey {
+			expectKey = true
+			common = append(common, item)
+			continue
+		}
+		if c, ok := item.([]Changes); ok {
+			rows = append(rows, c...)
+			multi = true
+			continue
+		}
+		if rv := reflect.ValueOf(item); rv.Kind() == reflect.Slice && s.model.isStructType(rv.Type().Elem()) {
+			if dataTypes == nil {
+				dataTypes = s.model.ColumnDataTypes()
+			}
+			for i := 0; i < rv.Len(); i++ {
+				rows = append(rows, s.model.structChanges(rv.Index(i), dataTypes, generatesValue))
+			}
+			multi = true
+			continue
+		}
+		if _, ok := item.(string); ok {
+			expectKey = false
+		}
+		common = append(common, item)
+	}
+	if !multi {
+		return nil
+	}
+	commonChanges := s.model.getChanges(common)
+	out := make([]Changes, 0, len(rows))
+	for _, row := range rows {
+		merged := Changes{}
+		for _, changes := range commonChanges {
+			for field, value := range changes {
+				merged[field] = value
+			}
+		}
+		for field, value := range row {
+			merged[field] = value
+		}
+		out = append(out, merged)
+	}
+	return out
+}
+
+// rowsColumns returns the regular fields and the JSONB columns used by any of
+// the rows, in the order of the model's fields.
+func (s *InsertSQL) rowsColumns(rows []Changes) (fields []Field, jsonbColumns []string) {
+	names := map[string]bool{}
+	jsonbs := map[string]bool{}
+	for _, row := range rows {
+		for field := range row {
+			if field.Jsonb != "" {
+				jsonbs[field.Jsonb] = true
+			} else {
+				names[field.Name] = true
+			}
+		}
+	}
+	for _, field := range s.model.modelFields {
+		if field.Jsonb == "" && names[field.Name] {
+			fields = append(fields, field)
+		}
+	}
+	for _, column := range s.model.jsonbColumns {
+		if jsonbs[column] {
+			jsonbColumns = append(jsonbColumns, column)
+		}
+	}
+	return
+}
+
+// rowValues renders the VALUES tuple of a row, numbering parameters from
+// start, and returns the tuple and its parameter values.
+func rowValues(row Changes, fields []Field, jsonbColumns []string, start int) (string, []interface{}) {
+	byName := map[string]interface{}{}
+	jsonbValues := map[string]Changes{}
+	for field, value := range row {
+		if field.Jsonb == "" {
+			if _, ok := value.(remove); !ok {
+				byName[field.Name] = value
+			}
+			continue
+		}
+		if _, ok := jsonbValues[field.Jsonb]; !ok {
+			jsonbValues[field.Jsonb] = Changes{}
+		}
+		jsonbValues[field.Jsonb][field] = value
+	}
+	numbers := []string{}
+	values := []interface{}{}
+	for _, field := range fields {
+		value, ok := byName[field.Name]
+		if !ok {
+			numbers = append(numbers, "DEFAULT")
+			continue
+		}
+		values = append(values, value)
+		numbers = append(numbers, fmt.Sprintf("$%d", start+len(values)-1))
+	}
+	for _, column := range jsonbColumns {
+		changes, ok := jsonbValues[column]
+		if !ok {
+			numbers = append(numbers, "DEFAULT")
+			continue
+		}
+		j, _ := json.Marshal(jsonbObject(changes))
+		values = append(values, string(j))
+		numbers = append(numbers, fmt.Sprintf("$%d", start+len(values)-1))
+	}
+	return "(" + strings.Join(numbers, ", ") + ")", values
+}
+
+// rowsStringValues renders a multi-row INSERT of all rows in one statement.
+func (s *InsertSQL) rowsStringValues(rows []Changes) (string, []interface{}) {
+	stmts := s.rowsStatements(rows, 0)
+	if len(stmts) == 0 {
+		return "", nil
+	}
+	return stmts[0].sql, stmts[0].values
+}
+
+// rowsStatements renders a multi-row INSERT, split into statements of at
+// most maxParams parameters each. Zero maxParams means no limit.
+func (s *InsertSQL) rowsStatements(rows []Changes, maxParams int) (stmts []statement) {
+	fields, jsonbColumns := s.rowsColumns(rows)
+	columns := []string{}
+	for _, field := range fields {
+		columns = append(columns, field.ColumnName)
+	}
+	columns = append(columns, jsonbColumns...)
+	if len(columns) == 0 {
+		return
+	}
+	prefix := "INSERT INTO " + s.model.tableName + " (" + strings.Join(columns, ", ") + ") VALUES "
+	var tuples []string
+	var values []interface{}
+	flush := func() {
+		suffix, args := s.onConflictReturning(columns, len(values))
+		sql, vals := s.model.convertValues(prefix+strings.Join(tuples, ", ")+suffix, append(values, args...))
+		stmts = append(stmts, statement{sql: sql, values: vals})
+	}
+	if maxParams > 0 {
+		maxParams -= len(s.args)
+	}
+	for _, row := range rows {
+		tuple, vals := rowValues(row, fields, jsonbColumns, len(values)+1)
+		if maxParams > 0 && len(tuples) > 0 && len(values)+len(vals) > maxParams {
+			flush()
+			tuples, values = nil, nil
+			tuple, vals = rowValues(row, fields, jsonbColumns, 1)
+		}
+		tuples = append(tuples, tuple)
+		values = append(values, vals...)
+	}
+	if len(tuples) > 0 {
+		flush()
+	}
+	return
+}
+
+// batches splits a multi-row INSERT that exceeds the parameter limit of
+// PostgreSQL into several statements.
+func (s *InsertSQL) batches() []statement {
+	rows := s.getRows()
+	if rows == nil {
+		return nil
+	}
+	return s.rowsStatements(rows, maxParameters)
 }
@@ -2,9 +2,9 @@ package psql
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"regexp"
-	"strconv"
+	"reflect"
 	"strings"
 )
 
@@ -24,8 +24,17 @@ type (
 		orderBy string
 		limit   string
 		offset  string
+		scope   softDeleteScope
+
+		// selection is the fields chosen by Only or Except, which are
+		// selected and scanned instead of all fields of the model.
+		selection *modelInfo
 	}
 
+	// softDeleteScope is the set of rows read by a SELECT of a model with a
+	// soft delete column.
+	softDeleteScope int
+
 	sqlConditions struct {
 		conditions []string
 		args       []interface{}
@@ -47,6 +56,97 @@ func (s SQL) AsSelect(fields ...string) *SelectSQL {
 	return f
 }
 
+const (
+	scopeNotDeleted softDeleteScope = iota
+	scopeAll
+	scopeDeleted
+)
+
+type (
+	fieldSelector struct {
+		names  []string
+		except bool
+	}
+)
+
+// Only is an option for Find to select only the given struct fields. Fields
+// stored in a JSONB column can be given by name, as "Column.Field" (e.g.
+// "Meta.Theme") or all at once by the JSONB column name (e.g. "Meta"). Other
+// fields of the results are left at their zero values.
+//
+//	var users []User
+//	users.Find(psql.Only("Id", "Name", "Meta.Theme")).MustQuery(&users)
+func Only(fieldNames ...string) fieldSelector {
+	return fieldSelector{names: fieldNames}
+}
+
+// Except is an option for Find to select all struct fields except the given
+// ones. See Only for the accepted field names.
+//
+//	users.Find(psql.Except("PasswordHash")).MustQuery(&users)
+func Except(fieldNames ...string) fieldSelector {
+	return fieldSelector{names: fieldNames, except: true}
+}
+
+func (fs fieldSelector) matches(m *Model, f Field) bool {
+	for _, name := range fs.names {
+		if name == f.Name {
+			return true
+		}
+		if f.Jsonb == "" {
+			continue
+		}
+		column, field := name, ""
+		if idx := strings.Index(name, "."); idx > -1 {
+			column, field = name[:idx], name[idx+1:]
+		}
+		if m.ToColumnName(column) == f.Jsonb && (field == "" || field == f.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectFields returns the model info with only the fields (and the JSONB
+// columns of the fields) chosen by the selector. An error is returned for
+// unknown field names.
+func (fs fieldSelector) selectFields(m *Model) (*modelInfo, error) {
+	for _, name := range fs.names {
+		if !(fieldSelector{names: []string{name}}).matchesAny(m) {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+	}
+	mi := &modelInfo{
+		columnNamer: m.columnNamer,
+		tableName:   m.tableName,
+	}
+	jsonbColumns := map[string]bool{}
+	for _, f := range m.modelFields {
+		if fs.matches(m, f) == fs.except {
+			continue
+		}
+		mi.modelFields = append(mi.modelFields, f)
+		if f.Jsonb != "" {
+			jsonbColumns[f.Jsonb] = true
+		}
+	}
+	for _, column := range m.jsonbColumns {
+		if jsonbColumns[column] {
+			mi.jsonbColumns = append(mi.jsonbColumns, column)
+		}
+	}
+	return mi, nil
+}
+
+func (fs fieldSelector) matchesAny(m *Model) bool {
+	for _, f := range m.modelFields {
+		if fs.matches(m, f) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m Model) newSelect(fields ...string) *SelectSQL {
 	return m.NewSQL("").AsSelect(fields...)
 }
@@ -63,7 +163,8 @@ func (m Model) newSelect(fields ...string) *SelectSQL {
 //	psql.NewModel(User{}, conn).Find().Where("id = $1", 1).MustQuery(&user)
 //
 // Options can include field transformation functions like AddTableName, which
-// prefixes each column with the table name for use in JOIN queries.
+// prefixes each column with the table name for use in JOIN queries, and Only
+// or Except to select a subset of the struct fields for this query.
 func (m Model) Find(options ...interface{}) *SelectSQL {
 	return m.newSelect().Find(options...)
 }
@@ -131,18 +232,37 @@ func (m Model) WHERE(args ...interface{}) *SelectSQL {
 }
 
 // Find populates the SELECT clause with all columns from the Model's struct.
-// Options can include transformation functions like AddTableName or the string
-// "--no-reset" to append rather than replace existing columns.
+// Options can include transformation functions like AddTableName, Only or
+// Except to select a subset of the struct fields, or the string "--no-reset"
+// to append rather than replace existing columns. Only and Except change the
+// columns selected and scanned, but the rest of the query (like WHERE, or
+// Update and Delete derived from it) still uses all fields of the model. The
+// query returns an error for unknown field names of Only and Except.
 func (s *SelectSQL) Find(options ...interface{}) *SelectSQL {
+	s.selection = nil
+	for _, opts := range options {
+		if fs, ok := opts.(fieldSelector); ok {
+			selection, err := fs.selectFields(s.model)
+			if err != nil {
+				s.setErr(err)
+				continue
+			}
+			s.selection = selection
+		}
+	}
+	mi := s.selectedFields()
+	if mi == nil {
+		mi = s.model.modelInfo
+	}
 	fields := []string{}
-	for _, field := range s.model.modelFields {
+	for _, field := range mi.modelFields {
 		if field.Jsonb != "" {
 			continue
 		}
 		fields = append(fields, field.ColumnName)
 	}
 	s.jfCount = 0
-	for _, jsonbField := range s.model.jsonbColumns {
+	for _, jsonbField := range mi.jsonbColumns {
 		fields = append(fields, jsonbField)
 		s.jfCount += 1
 	}
@@ -163,19 +283,28 @@ func (s *SelectSQL) Find(options ...interface{}) *SelectSQL {
 	return s.ResetSelect(fields...)
 }
 
-// Update converts this SelectSQL to an UpdateSQL, preserving WHERE conditions.
+// selectedFields returns the fields chosen by Only or Except, or nil.
+func (s *SelectSQL) selectedFields() *modelInfo {
+	return s.selection
+}
+
+// Update converts this SelectSQL to an UpdateSQL, preserving WHERE conditions
+// and the soft delete scope (see Unscoped and OnlyDeleted).
 func (s *SelectSQL) Update(lotsOfChanges ...interface{}) *UpdateSQL {
 	n := s.model.Update(lotsOfChanges...)
 	n.conditions = s.conditions
 	n.args = s.args
+	n.scope = s.scopeCondition(false)
 	return n
 }
 
-// Delete converts this SelectSQL to a DeleteSQL, preserving WHERE conditions.
+// Delete converts this SelectSQL to a DeleteSQL, preserving WHERE conditions
+// and the soft delete scope (see Unscoped and OnlyDeleted).
 func (s *SelectSQL) Delete() *DeleteSQL {
 	n := s.model.Delete()
 	n.conditions = s.conditions
 	n.args = s.args
+	n.scope = s.scopeCondition(false)
 	return n
 }
 
@@ -185,6 +314,12 @@ func (s *SelectSQL) MustExists() bool {
 	return s.MustExistsCtxTx(context.Background(), nil)
 }
 
+// MustExistsCtx is like ExistsCtx but panics if existence check operation
+// fails.
+func (s *SelectSQL) MustExistsCtx(ctx context.Context) bool {
+	return s.MustExistsCtxTx(ctx, nil)
+}
+
 // MustExistsCtxTx is like ExistsCtxTx but panics if existence check operation fails.
 // Returns true if record exists, false if not exists.
 func (s *SelectSQL) MustExistsCtxTx(ctx context.Context, tx Tx) bool {
@@ -201,10 +336,16 @@ func (s *SelectSQL) Exists() (exists bool, err error) {
 	return s.ExistsCtxTx(context.Background(), nil)
 }
 
+// ExistsCtx is like Exists but accepts a context. The query executes within
+// the transaction of the context, if any.
+func (s *SelectSQL) ExistsCtx(ctx context.Context) (bool, error) {
+	return s.ExistsCtxTx(ctx, nil)
+}
+
 // ExistsCtxTx is like Exists but accepts a context and optional transaction.
 func (s *SelectSQL) ExistsCtxTx(ctx context.Context, tx Tx) (exists bool, err error) {
 	var ret int
-	err = s.ResetSelect("1 AS one").QueryRowCtxTx(ctx, tx, &ret)
+	err = s.selectOnly("1 AS one").QueryRowCtxTx(ctx, tx, &ret)
 	if err == s.model.connection.ErrNoRows() {
 		err = nil
 		return
@@ -218,6 +359,11 @@ func (s *SelectSQL) MustCount(optional ...string) int {
 	return s.MustCountCtxTx(context.Background(), nil, optional...)
 }
 
+// MustCountCtx is like CountCtx but panics if count operation fails.
+func (s *SelectSQL) MustCountCtx(ctx context.Context, optional ...string) int {
+	return s.MustCountCtxTx(ctx, nil, optional...)
+}
+
 // MustCountCtxTx is like CountCtxTx but panics if count operation fails.
 func (s *SelectSQL) MustCountCtxTx(ctx context.Context, tx Tx, optional ...string) int {
 	count, err := s.CountCtxTx(ctx, tx, optional...)
@@ -234,6 +380,12 @@ func (s *SelectSQL) Count(optional ...string) (count int, err error) {
 	return s.CountCtxTx(context.Background(), nil, optional...)
 }
 
+// CountCtx is like Count but accepts a context. The query executes within
+// the transaction of the context, if any.
+func (s *SelectSQL) CountCtx(ctx context.Context, optional ...string) (int, error) {
+	return s.CountCtxTx(ctx, nil, optional...)
+}
+
 // CountCtxTx is like Count but accepts a context and optional transaction.
 func (s *SelectSQL) CountCtxTx(ctx context.Context, tx Tx, optional ...string) (count int, err error) {
 	var expr string
@@ -242,10 +394,299 @@ func (s *SelectSQL) CountCtxTx(ctx context.Context, tx Tx, optional ...string) (
 	} else {
 		expr = "COUNT(*)"
 	}
-	err = s.ResetSelect(expr).QueryRowCtxTx(ctx, tx, &count)
+	err = s.selectOnly(expr).QueryRowCtxTx(ctx, tx, &count)
 	return
 }
 
+// MustSum is like Sum but panics if sum operation fails.
+func (s *SelectSQL) MustSum(field string, dest interface{}) {
+	s.MustSumCtxTx(context.Background(), nil, field, dest)
+}
+
+// MustSumCtx is like SumCtx but panics if sum operation fails.
+func (s *SelectSQL) MustSumCtx(ctx context.Context, field string, dest interface{}) {
+	s.MustSumCtxTx(ctx, nil, field, dest)
+}
+
+// MustSumCtxTx is like SumCtxTx but panics if sum operation fails.
+func (s *SelectSQL) MustSumCtxTx(ctx context.Context, tx Tx, field string, dest interface{}) {
+	if err := s.SumCtxTx(ctx, tx, field, dest); err != nil {
+		panic(err)
+	}
+}
+
+// Sum executes a SELECT SUM(...) query and scans the result into dest, which
+// must be a pointer. The field can be a struct field name (JSONB fields are
+// cast to the type of the struct field) or any SQL expression. If no rows
+// match, dest is set to its zero value.
+//
+//	var total int
+//	orders.Where("status = $1", "paid").MustSum("TotalAmount", &total)
+func (s *SelectSQL) Sum(field string, dest interface{}) error {
+	return s.SumCtxTx(context.Background(), nil, field, dest)
+}
+
+// SumCtx is like Sum but accepts a context. The query executes within the
+// transaction of the context, if any.
+func (s *SelectSQL) SumCtx(ctx context.Context, field string, dest interface{}) error {
+	return s.SumCtxTx(ctx, nil, field, dest)
+}
+
+// SumCtxTx is like Sum but accepts a context and optional transaction.
+func (s *SelectSQL) SumCtxTx(ctx context.Context, tx Tx, field string, dest interface{}) error {
+	return s.aggregateCtxTx(ctx, tx, "SUM", field, dest)
+}
+
+// MustAvg is like Avg but panics if avg operation fails.
+func (s *SelectSQL) MustAvg(field string, dest interface{}) {
+	s.MustAvgCtxTx(context.Background(), nil, field, dest)
+}
+
+// MustAvgCtx is like AvgCtx but panics if avg operation fails.
+func (s *SelectSQL) MustAvgCtx(ctx context.Context, field string, dest interface{}) {
+	s.MustAvgCtxTx(ctx, nil, field, dest)
+}
+
+// MustAvgCtxTx is like AvgCtxTx but panics if avg operation fails.
+func (s *SelectSQL) MustAvgCtxTx(ctx context.Context, tx Tx, field string, dest interface{}) {
+	if err := s.AvgCtxTx(ctx, tx, field, dest); err != nil {
+		panic(err)
+	}
+}
+
+// Avg executes a SELECT AVG(...) query and scans the result into dest. See
+// Sum for details about field and dest.
+func (s *SelectSQL) Avg(field string, dest interface{}) error {
+	return s.AvgCtxTx(context.Background(), nil, field, dest)
+}
+
+// AvgCtx is like Avg but accepts a context. The query executes within the
+// transaction of the context, if any.
+func (s *SelectSQL) AvgCtx(ctx context.Context, field string, dest interface{}) error {
+	return s.AvgCtxTx(ctx, nil, field, dest)
+}
+
+// AvgCtxTx is like Avg but accepts a context and optional transaction.
+func (s *SelectSQL) AvgCtxTx(ctx context.Context, tx Tx, field string, dest interface{}) error {
+	return s.aggregateCtxTx(ctx, tx, "AVG", field, dest)
+}
+
+// MustMin is like Min but panics if min operation fails.
+func (s *SelectSQL) MustMin(field string, dest interface{}) {
+	s.MustMinCtxTx(context.Background(), nil, field, dest)
+}
+
+// MustMinCtx is like MinCtx but panics if min operation fails.
+func (s *SelectSQL) MustMinCtx(ctx context.Context, field string, dest interface{}) {
+	s.MustMinCtxTx(ctx, nil, field, dest)
+}
+
+// MustMinCtxTx is like MinCtxTx but panics if min operation fails.
+func (s *SelectSQL) MustMinCtxTx(ctx context.Context, tx Tx, field string, dest interface{}) {
+	if err := s.MinCtxTx(ctx, tx, field, dest); err != nil {
+		panic(err)
+	}
+}
+
+// Min executes a SELECT MIN(...) query and scans the result into dest. See
+// Sum for details about field and dest.
+func (s *SelectSQL) Min(field string, dest interface{}) error {
+	return s.MinCtxTx(context.Background(), nil, field, dest)
+}
+
+// MinCtx is like Min but accepts a context. The query executes within the
+// transaction of the context, if any.
+func (s *SelectSQL) MinCtx(ctx context.Context, field string, dest interface{}) error {
+	return s.MinCtxTx(ctx, nil, field, dest)
+}
+
+// MinCtxTx is like Min but accepts a context and optional transaction.
+func (s *SelectSQL) MinCtxTx(ctx context.Context, tx Tx, field string, dest interface{}) error {
+	return s.aggregateCtxTx(ctx, tx, "MIN", field, dest)
+}
+
+// MustMax is like Max but panics if max operation fails.
+func (s *SelectSQL) MustMax(field string, dest interface{}) {
+	s.MustMaxCtxTx(context.Background(), nil, field, dest)
+}
+
+// MustMaxCtx is like MaxCtx but panics if max operation fails.
+func (s *SelectSQL) MustMaxCtx(ctx context.Context, field string, dest interface{}) {
+	s.MustMaxCtxTx(ctx, nil, field, dest)
+}
+
+// MustMaxCtxTx is like MaxCtxTx but panics if max operation fails.
+func (s *SelectSQL) MustMaxCtxTx(ctx context.Context, tx Tx, field string, dest interface{}) {
+	if err := s.MaxCtxTx(ctx, tx, field, dest); err != nil {
+		panic(err)
+	}
+}
+
+// Max executes a SELECT MAX(...) query and scans the result into dest. See
+// Sum for details about field and dest.
+func (s *SelectSQL) Max(field string, dest interface{}) error {
+	return s.MaxCtxTx(context.Background(), nil, field, dest)
+}
+
+// MaxCtx is like Max but accepts a context. The query executes within the
+// transaction of the context, if any.
+func (s *SelectSQL) MaxCtx(ctx context.Context, field string, dest interface{}) error {
+	return s.MaxCtxTx(ctx, nil, field, dest)
+}
+
+// MaxCtxTx is like Max but accepts a context and optional transaction.
+func (s *SelectSQL) MaxCtxTx(ctx context.Context, tx Tx, field string, dest interface{}) error {
+	return s.aggregateCtxTx(ctx, tx, "MAX", field, dest)
+}
+
+// aggregateCtxTx selects function(field) and scans the result into dest. The
+// value is scanned through a pointer so that NULL (aggregate of an empty set)
+// leaves dest at its zero value.
+func (s *SelectSQL) aggregateCtxTx(ctx context.Context, tx Tx, function, field string, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr {
+		return ErrMustBePointer
+	}
+	query := s.selectOnly(function + "(" + s.model.FieldExpr(field) + ")")
+	elem := rv.Elem()
+	if elem.Kind() == reflect.Ptr {
+		return query.QueryRowCtxTx(ctx, tx, dest)
+	}
+	nullable := reflect.New(reflect.PtrTo(elem.Type()))
+	if err := query.QueryRowCtxTx(ctx, tx, nullable.Interface()); err != nil {
+		return err
+	}
+	if value := nullable.Elem(); value.IsNil() {
+		elem.Set(reflect.Zero(elem.Type()))
+	} else {
+		elem.Set(value.Elem())
+	}
+	return nil
+}
+
+// MustPluck is like Pluck but panics if pluck operation fails.
+func (s *SelectSQL) MustPluck(field string, target interface{}) {
+	s.MustPluckCtxTx(context.Background(), nil, field, target)
+}
+
+// MustPluckCtx is like PluckCtx but panics if pluck operation fails.
+func (s *SelectSQL) MustPluckCtx(ctx context.Context, field string, target interface{}) {
+	s.MustPluckCtxTx(ctx, nil, field, target)
+}
+
+// MustPluckCtxTx is like PluckCtxTx but panics if pluck operation fails.
+func (s *SelectSQL) MustPluckCtxTx(ctx context.Context, tx Tx, field string, target interface{}) {
+	if err := s.PluckCtxTx(ctx, tx, field, target); err != nil {
+		panic(err)
+	}
+}
+
+// Pluck selects a single field from the matching rows and appends the values
+// to target, which must be a pointer to a slice. The field can be a struct
+// field name (including JSONB fields) or any SQL expression.
+//
+//	var names []string
+//	users.Where("age > $1", 18).OrderBy("id").MustPluck("Name", &names)
+func (s *SelectSQL) Pluck(field string, target interface{}) error {
+	return s.PluckCtxTx(context.Background(), nil, field, target)
+}
+
+// PluckCtx is like Pluck but accepts a context. The query executes within the
+// transaction of the context, if any.
+func (s *SelectSQL) PluckCtx(ctx context.Context, field string, target interface{}) error {
+	return s.PluckCtxTx(ctx, nil, field, target)
+}
+
+// PluckCtxTx is like Pluck but accepts a context and optional transaction.
+func (s *SelectSQL) PluckCtxTx(ctx context.Context, tx Tx, field string, target interface{}) error {
+	f := s.model.FieldByName(field)
+	if f == nil || f.Jsonb == "" || jsonbCastType(f.ColumnType) != "jsonb" {
+		return s.selectOnly(s.model.FieldExpr(field)).QueryCtxTx(ctx, tx, target)
+	}
+	// slices, maps and structs in JSONB can't be scanned by drivers, so they
+	// are selected as JSON text and unmarshaled
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return ErrInvalidTarget
+	}
+	var values []string
+	expr := "COALESCE(" + f.expr() + ", 'null')::text"
+	if err := s.selectOnly(expr).QueryCtxTx(ctx, tx, &values); err != nil {
+		return err
+	}
+	slice := rv.Elem()
+	for _, value := range values {
+		nv := reflect.New(slice.Type().Elem())
+		if err := json.Unmarshal([]byte(value), nv.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, nv.Elem()))
+	}
+	return nil
+}
+
+// selectOnly returns a copy of the query that selects only the given
+// expressions. Unlike ResetSelect, the SELECT list of s is left untouched.
+func (s *SelectSQL) selectOnly(expressions ...string) *SelectSQL {
+	sql := *s.SQL
+	n := *s
+	n.SQL = &sql
+	n.SQL.main = &n
+	n.fields = expressions
+	n.jfCount = 0
+	return &n
+}
+
+// Unscoped includes soft deleted rows in the query. By default, queries of
+// a model with a soft delete column (see DeleteSQL) only read rows that are
+// not deleted.
+//
+//	users.Find().Unscoped().Where("id = $1", 1).MustQuery(&user)
+func (s *SelectSQL) Unscoped() *SelectSQL {
+	s.scope = scopeAll
+	return s
+}
+
+// OnlyDeleted limits the query to soft deleted rows.
+//
+//	count := users.Select().OnlyDeleted().MustCount()
+func (s *SelectSQL) OnlyDeleted() *SelectSQL {
+	s.scope = scopeDeleted
+	return s
+}
+
+// scopedConditions returns the conditions of the query with the condition of
+// the soft delete scope appended. Raw SQL queries are not scoped. The column
+// is prefixed with the table name if the query has other FROM items or JOIN
+// clauses.
+func (s *SelectSQL) scopedConditions() sqlConditions {
+	condition := s.scopeCondition(s.from != "" || s.join != "")
+	if condition == "" {
+		return s.sqlConditions
+	}
+	conditions := s.sqlConditions
+	conditions.conditions = append(conditions.conditions[:len(conditions.conditions):len(conditions.conditions)], condition)
+	return conditions
+}
+
+// scopeCondition returns the condition of the soft delete scope, or an empty
+// string if the query is not scoped. The column is prefixed with the table
+// name if qualified is true.
+func (s *SelectSQL) scopeCondition(qualified bool) string {
+	field, ok := s.model.softDeleteField()
+	if !ok || s.sql != "" || s.scope == scopeAll {
+		return ""
+	}
+	column := field.ColumnName
+	if qualified {
+		column = s.model.tableName + "." + column
+	}
+	if s.scope == scopeDeleted {
+		return column + " IS NOT NULL"
+	}
+	return column + " IS NULL"
+}
+
 // ResetSelect replaces all SELECT columns with the given expressions.
 func (s *SelectSQL) ResetSelect(expressions ...string) *SelectSQL {
 	s.fields = expressions
@@ -291,9 +732,24 @@ func (s *SelectSQL) Having(condition string, args ...interface{}) *SelectSQL {
 	return s
 }
 
-// OrderBy adds an ORDER BY clause to the query.
+// OrderBy adds an ORDER BY clause to the query. If an expression starts with
+// a struct field name, the name is replaced with the field's expression (see
+// Model.FieldExpr), so fields stored in JSONB columns can be used directly.
+//
+//	users.Find().OrderBy("Age DESC", "id") // ORDER BY (meta->>'age')::bigint DESC, id
 func (s *SelectSQL) OrderBy(expressions ...string) *SelectSQL {
-	s.orderBy = strings.Join(expressions, ", ")
+	exprs := make([]string, len(expressions))
+	for i, expression := range expressions {
+		name, rest := expression, ""
+		if idx := strings.Index(expression, " "); idx > -1 {
+			name, rest = expression[:idx], expression[idx:]
+		}
+		if f := s.model.FieldByName(name); f != nil {
+			expression = f.expr() + rest
+		}
+		exprs[i] = expression
+	}
+	s.orderBy = strings.Join(exprs, ", ")
 	return s
 }
 
@@ -331,6 +787,15 @@ func (s *SelectSQL) Where(condition string, args ...interface{}) *SelectSQL {
 // WHERE adds conditions from field/operator/value tuples. Each tuple consists
 // of three consecutive arguments: field name, operator, and value. Multiple
 // tuples are combined with AND.
+//
+// Fields stored in JSONB columns are translated to their JSONB expressions
+// (see Model.FieldExpr). The containment and existence operators @>, <@, ?,
+// ?| and ?& compare the JSONB value itself; values for @> and <@ are encoded
+// to JSON and the slices of keys for ?| and ?& to a text array. If a value
+// can't be encoded, the error is returned when the query is executed.
+//
+//	users.Find().WHERE("Age", ">=", 18)             // (meta->>'age')::bigint >= $1
+//	users.Find().WHERE("Tags", "@>", []string{"a"}) // meta->'tags' @> $1::jsonb
 func (s *SelectSQL) WHERE(args ...interface{}) *SelectSQL {
 	for i := 0; i < len(args)/3; i++ {
 		var column string
@@ -344,8 +809,13 @@ func (s *SelectSQL) WHERE(args ...interface{}) *SelectSQL {
 		if column == "" || operator == "" {
 			continue
 		}
-		s.args = append(s.args, args[i*3+2])
-		s.conditions = append(s.conditions, fmt.Sprintf("%s %s $%d", s.model.ToColumnName(column), operator, len(s.args)))
+		condition, arg, err := s.model.tupleCondition(column, operator, args[i*3+2], len(s.args)+1)
+		if err != nil {
+			s.setErr(err)
+			continue
+		}
+		s.args = append(s.args, arg)
+		s.conditions = append(s.conditions, condition)
 	}
 	return s
 }
@@ -390,16 +860,7 @@ func (s *SelectSQL) With(expression string, args ...interface{}) *SelectSQL {
 		expression = strings.Replace(expression, "$?", fmt.Sprintf("$%d", i), 1)
 		i += 1
 	}
-	if offset := len(s.args); offset > 0 {
-		re := regexp.MustCompile(`\$(\d+)`)
-		expression = re.ReplaceAllStringFunc(expression, func(s string) string {
-			num, err := strconv.Atoi(s[1:])
-			if err != nil { // this should not happen
-				panic(err)
-			}
-			return fmt.Sprintf("$%d", num+offset)
-		})
-	}
+	expression = shiftParameters(expression, len(s.args))
 	if s.with != "" {
 		s.with += ", "
 	}
@@ -412,16 +873,7 @@ func (s *SelectSQL) With(expression string, args ...interface{}) *SelectSQL {
 // "AS MATERIALIZED" or "AS NOT MATERIALIZED" for PostgreSQL 12+.
 func (s *SelectSQL) WITH(name string, sql *SelectSQL) *SelectSQL {
 	sqlQuery := sql.String()
-	if offset := len(s.args); offset > 0 {
-		re := regexp.MustCompile(`\$(\d+)`)
-		sqlQuery = re.ReplaceAllStringFunc(sqlQuery, func(s string) string {
-			num, err := strconv.Atoi(s[1:])
-			if err != nil { // this should not happen
-				panic(err)
-			}
-			return fmt.Sprintf("$%d", num+offset)
-		})
-	}
+	sqlQuery = shiftParameters(sqlQuery, len(s.args))
 	if s.with != "" {
 		s.with += ", "
 	}
@@ -481,7 +933,7 @@ func (s *SelectSQL) String() string {
 	if s.join != "" {
 		sql += " " + s.join
 	}
-	sql += s.where()
+	sql += s.scopedConditions().where()
 	if s.groupBy != "" {
 		sql += " GROUP BY " + s.groupBy + s.having()
 	}
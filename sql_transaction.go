@@ -4,14 +4,126 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopsql/db"
+)
+
+// Transaction isolation levels for TxOptions.
+const (
+	ReadUncommitted = db.LevelReadUncommitted
+	ReadCommitted   = db.LevelReadCommitted
+	RepeatableRead  = db.LevelRepeatableRead
+	Serializable    = db.LevelSerializable
+)
+
+var (
+	// ErrInvalidIsolation is returned by TransactionOptsCtx when the
+	// isolation level of TxOptions is not one of ReadUncommitted,
+	// ReadCommitted, RepeatableRead and Serializable.
+	ErrInvalidIsolation = errors.New("invalid transaction isolation level")
+
+	// ErrUnknownTransaction is returned by AfterCommit and AfterRollback
+	// within a savepoint of a transaction that was not started by a
+	// transaction block, whose commit or rollback can not be observed.
+	ErrUnknownTransaction = errors.New("transaction was not started by a transaction block")
 )
 
 type (
 	// TransactionBlock is a function executed within a database transaction.
 	// Return nil to commit; return an error or panic to rollback.
 	TransactionBlock func(context.Context, Tx) error
+
+	// TxOptions are the options of a transaction started by
+	// TransactionOptsCtx. The zero value uses the defaults of the database
+	// (usually READ COMMITTED and READ WRITE).
+	TxOptions struct {
+		// Isolation is the isolation level, like Serializable. Empty for
+		// the default level.
+		Isolation string
+		// ReadOnly starts a READ ONLY transaction.
+		ReadOnly bool
+		// Deferrable starts a DEFERRABLE transaction, which only has an
+		// effect on SERIALIZABLE READ ONLY transactions.
+		Deferrable bool
+	}
+
+	// RetryPolicy is the policy of TransactionRetryCtx. Zero fields use the
+	// defaults.
+	RetryPolicy struct {
+		// Options are the options of every attempt's transaction.
+		Options TxOptions
+		// MaxAttempts is the maximum number of attempts, including the
+		// first one. Defaults to 3.
+		MaxAttempts int
+		// MinBackoff is the wait before the second attempt; it doubles
+		// after each attempt. Defaults to 10ms.
+		MinBackoff time.Duration
+		// MaxBackoff is the maximum wait between two attempts. Defaults to
+		// 1s.
+		MaxBackoff time.Duration
+	}
+
+	// HookError is returned by a transaction block whose AfterCommit or
+	// AfterRollback functions panicked. Err is the error of the transaction
+	// itself, or nil if the transaction was committed.
+	HookError struct {
+		Err    error
+		Panics []error
+	}
+)
+
+// Error returns the error of the transaction, if any, followed by the
+// panics of the hooks.
+func (e *HookError) Error() string {
+	var msgs []string
+	if e.Err != nil {
+		msgs = append(msgs, e.Err.Error())
+	}
+	for _, p := range e.Panics {
+		msgs = append(msgs, p.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the error of the transaction.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// hookError returns err, or a HookError of err if hooks panicked.
+func hookError(err error, panics []error) error {
+	if len(panics) == 0 {
+		return err
+	}
+	return &HookError{Err: err, Panics: panics}
+}
+
+// Error codes of the errors retried by TransactionRetryCtx.
+const (
+	errCodeSerializationFailure = "40001"
+	errCodeDeadlockDetected     = "40P01"
 )
 
+// String returns the transaction mode of the options, like "ISOLATION LEVEL
+// SERIALIZABLE READ ONLY DEFERRABLE", or an empty string for the defaults.
+func (o TxOptions) String() string {
+	var modes []string
+	if o.Isolation != "" {
+		modes = append(modes, "ISOLATION LEVEL "+strings.ToUpper(o.Isolation))
+	}
+	if o.ReadOnly {
+		modes = append(modes, "READ ONLY")
+	}
+	if o.Deferrable {
+		modes = append(modes, "DEFERRABLE")
+	}
+	return strings.Join(modes, " ")
+}
+
 // MustTransaction is like Transaction but panics if the transaction fails.
 func (m Model) MustTransaction(block TransactionBlock) {
 	if err := m.Transaction(block); err != nil {
@@ -43,22 +155,350 @@ func (m Model) MustTransactionCtx(ctx context.Context, block TransactionBlock) {
 //		users.Insert("Name", "Bob").MustExecuteCtxTx(ctx, tx)
 //		return nil // commit
 //	})
-func (m Model) TransactionCtx(ctx context.Context, block TransactionBlock) (err error) {
-	m.log("BEGIN", nil, 0)
+//
+// The context passed to the block carries the transaction, so that the
+// methods with a context but without a tx argument (like ExecuteCtx, QueryCtx
+// and CountCtx) execute within the transaction. If ctx already
+// carries one (TransactionCtx is called within another transaction block),
+// the block is executed within a savepoint of that transaction instead, so
+// that an error or panic of the inner block only rolls back the changes of
+// the inner block.
+//
+//	users.TransactionCtx(ctx, func(ctx context.Context, tx db.Tx) error {
+//		users.Insert("Name", "Alice").MustExecuteCtx(ctx)
+//		users.TransactionCtx(ctx, func(ctx context.Context, tx db.Tx) error {
+//			return errors.New("only this block is rolled back")
+//		}) // SAVEPOINT sp_1 ... ROLLBACK TO SAVEPOINT sp_1
+//		return nil // Alice is committed
+//	})
+func (m Model) TransactionCtx(ctx context.Context, block TransactionBlock) error {
+	return m.TransactionCtxTx(ctx, nil, block)
+}
+
+// MustTransactionCtxTx is like TransactionCtxTx but panics if the transaction
+// fails.
+func (m Model) MustTransactionCtxTx(ctx context.Context, tx Tx, block TransactionBlock) {
+	if err := m.TransactionCtxTx(ctx, tx, block); err != nil {
+		panic(err)
+	}
+}
+
+// TransactionCtxTx is like TransactionCtx but executes the block within a
+// savepoint of tx if tx is not nil. If tx was not started by a transaction
+// block, AfterCommit and AfterRollback return ErrUnknownTransaction within the
+// block.
+func (m Model) TransactionCtxTx(ctx context.Context, tx Tx, block TransactionBlock) error {
+	current, ok := ctx.Value(txContextKey{}).(txContext)
+	if tx == nil && ok {
+		tx = current.tx
+	}
+	if tx = m.ownTx(tx); tx == nil {
+		return m.begin(ctx, TxOptions{}, block)
+	}
+	var parent *txHooks
+	if current.tx == tx {
+		parent = current.hooks
+	}
+	return m.savepoint(ctx, tx, current.depth+1, parent, block)
+}
+
+// MustTransactionOptsCtx is like TransactionOptsCtx but panics if the
+// transaction fails.
+func (m Model) MustTransactionOptsCtx(ctx context.Context, opts TxOptions, block TransactionBlock) {
+	if err := m.TransactionOptsCtx(ctx, opts, block); err != nil {
+		panic(err)
+	}
+}
+
+// TransactionOptsCtx is like TransactionCtx but starts the transaction with
+// the given isolation level and access mode. The isolation level and read
+// only mode are passed to the BeginTx method of the connection, and all modes
+// are then set with one SET TRANSACTION statement, since not every driver
+// supports them. ErrInvalidIsolation is returned for an unknown isolation
+// level.
+//
+//	users.TransactionOptsCtx(ctx, psql.TxOptions{
+//		Isolation:  psql.Serializable,
+//		ReadOnly:   true,
+//		Deferrable: true,
+//	}, func(ctx context.Context, tx db.Tx) error {
+//		users.Select("COUNT(*)").MustQueryRowCtx(ctx, &count)
+//		return nil
+//	})
+//
+// The mode of a transaction can not be changed once it has started, so if
+// ctx already carries a transaction, the block is executed within a
+// savepoint of that transaction and opts are ignored, as in TransactionCtx.
+func (m Model) TransactionOptsCtx(ctx context.Context, opts TxOptions, block TransactionBlock) error {
+	if tx := m.ownTx(txFromContext(ctx)); tx != nil {
+		return m.TransactionCtxTx(ctx, tx, block)
+	}
+	return m.begin(ctx, opts, block)
+}
+
+type (
+	// txContextKey is the context key of the transaction of a transaction
+	// block.
+	txContextKey struct{}
+
+	// txContext is the transaction of a transaction block, the depth of its
+	// savepoint (zero for the transaction itself) and the hooks of the block.
+	txContext struct {
+		tx    Tx
+		depth int
+		hooks *txHooks
+	}
+
+	// txHooks are the functions registered by AfterCommit and AfterRollback
+	// within a transaction block.
+	txHooks struct {
+		mu            sync.Mutex
+		afterCommit   []func()
+		afterRollback []func()
+	}
+)
+
+// AfterCommit registers fn to be called after the transaction of ctx (the
+// context passed to a transaction block) is committed. Functions are called
+// in the order they are registered, after the COMMIT statement succeeds; they
+// are never called if the transaction is rolled back. Use it for side effects
+// that must only happen if the changes are saved, like sending emails or
+// invalidating caches.
+//
+//	users.TransactionCtx(ctx, func(ctx context.Context, tx db.Tx) error {
+//		users.Insert("Name", "Alice").MustExecuteCtx(ctx)
+//		psql.AfterCommit(ctx, func() { sendWelcomeEmail("Alice") })
+//		return nil
+//	})
+//
+// Functions registered within a savepoint (a nested transaction block) are
+// called after the whole transaction is committed, and are dropped if the
+// savepoint is rolled back. If ctx carries no transaction, fn is called
+// immediately. If the transaction of ctx was not started by a transaction
+// block (see TransactionCtxTx), fn is not registered and ErrUnknownTransaction
+// is returned. Panics of fn are recovered and logged, and the transaction
+// block returns a HookError whose Err is nil, as the transaction is committed
+// nonetheless.
+func AfterCommit(ctx context.Context, fn func()) error {
+	hooks, err := hooksFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if hooks != nil {
+		hooks.mu.Lock()
+		hooks.afterCommit = append(hooks.afterCommit, fn)
+		hooks.mu.Unlock()
+		return nil
+	}
+	fn()
+	return nil
+}
+
+// AfterRollback registers fn to be called after the transaction of ctx (the
+// context passed to a transaction block) is rolled back, including when its
+// COMMIT statement fails. Functions are called in the order they are
+// registered. Functions registered within a savepoint are called when the
+// savepoint is rolled back, or when the whole transaction is rolled back. If
+// ctx carries no transaction, fn is never called. As with AfterCommit,
+// ErrUnknownTransaction is returned if the transaction of ctx was not started
+// by a transaction block. Panics of fn are recovered and logged, and the
+// transaction block returns a HookError wrapping the error of the rollback.
+func AfterRollback(ctx context.Context, fn func()) error {
+	hooks, err := hooksFromContext(ctx)
+	if hooks != nil {
+		hooks.mu.Lock()
+		hooks.afterRollback = append(hooks.afterRollback, fn)
+		hooks.mu.Unlock()
+	}
+	return err
+}
+
+// hooksFromContext returns the hooks of the transaction block of the
+// context, or nil if the context carries no transaction.
+// ErrUnknownTransaction is returned if the context carries a transaction
+// without hooks.
+func hooksFromContext(ctx context.Context) (*txHooks, error) {
+	current, _ := ctx.Value(txContextKey{}).(txContext)
+	if current.tx != nil && current.hooks == nil {
+		return nil, ErrUnknownTransaction
+	}
+	return current.hooks, nil
+}
+
+// merge moves the hooks of a released savepoint to the hooks of its parent.
+func (h *txHooks) merge(child *txHooks) {
+	child.mu.Lock()
+	afterCommit, afterRollback := child.afterCommit, child.afterRollback
+	child.mu.Unlock()
+	h.mu.Lock()
+	h.afterCommit = append(h.afterCommit, afterCommit...)
+	h.afterRollback = append(h.afterRollback, afterRollback...)
+	h.mu.Unlock()
+}
+
+// runAfterCommit calls the functions registered by AfterCommit.
+func (m Model) runAfterCommit(hooks *txHooks) []error {
+	hooks.mu.Lock()
+	fns := hooks.afterCommit
+	hooks.mu.Unlock()
+	return m.runHooks("AfterCommit", fns)
+}
+
+// runAfterRollback calls the functions registered by AfterRollback.
+func (m Model) runAfterRollback(hooks *txHooks) []error {
+	hooks.mu.Lock()
+	fns := hooks.afterRollback
+	hooks.mu.Unlock()
+	return m.runHooks("AfterRollback", fns)
+}
+
+// runHooks calls each function, recovering and logging its panic so that
+// the remaining functions are still called. The panics are returned.
+func (m Model) runHooks(name string, fns []func()) (panics []error) {
+	for _, fn := range fns {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					p := fmt.Errorf("%s hook panicked: %v", name, r)
+					if m.logger != nil {
+						m.logger.Error(p.Error())
+					}
+					panics = append(panics, p)
+				}
+			}()
+			fn()
+		}()
+	}
+	return
+}
+
+// WithoutTx returns a copy of ctx without the transaction of a transaction
+// block, so that statements executed with the context (and transactions
+// started with it) are independent of the transaction.
+//
+//	users.TransactionCtx(ctx, func(ctx context.Context, tx db.Tx) error {
+//		users.Insert("Name", "Alice").MustExecuteCtx(ctx) // in the transaction
+//		auditLogs.Insert("Action", "insert").MustExecuteCtx(psql.WithoutTx(ctx)) // not rolled back
+//		return nil
+//	})
+func WithoutTx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txContextKey{}, txContext{})
+}
+
+// txFromContext returns the transaction of the context, or nil.
+func txFromContext(ctx context.Context) Tx {
+	if current, ok := ctx.Value(txContextKey{}).(txContext); ok {
+		return current.tx
+	}
+	return nil
+}
+
+// MustTransactionRetryCtx is like TransactionRetryCtx but panics if the
+// transaction fails.
+func (m Model) MustTransactionRetryCtx(ctx context.Context, policy RetryPolicy, block TransactionBlock) {
+	if err := m.TransactionRetryCtx(ctx, policy, block); err != nil {
+		panic(err)
+	}
+}
+
+// TransactionRetryCtx is like TransactionOptsCtx but reruns the whole
+// transaction if it fails with a serialization failure (40001) or a deadlock
+// (40P01), which are expected with SERIALIZABLE or REPEATABLE READ isolation.
+// The block may be executed several times, so it should not have side
+// effects outside of the transaction. Attempts are made up to
+// policy.MaxAttempts times, with a jittered exponential backoff between
+// them. If every attempt fails, the last error is returned wrapped with the
+// number of attempts; other errors are returned as is. The AfterRollback
+// hooks of a failed attempt are called before the next attempt.
+//
+//	err := users.TransactionRetryCtx(ctx, psql.RetryPolicy{
+//		Options:     psql.TxOptions{Isolation: psql.Serializable},
+//		MaxAttempts: 5,
+//	}, func(ctx context.Context, tx db.Tx) error {
+//		users.Update("Balance", 100).Where("id = $1", 1).MustExecuteCtx(ctx)
+//		return nil
+//	})
+//
+// A failed statement aborts the whole transaction, so if ctx already carries
+// a transaction, the block is executed once within a savepoint of that
+// transaction, as in TransactionCtx, and it is up to the outer transaction
+// to be retried.
+func (m Model) TransactionRetryCtx(ctx context.Context, policy RetryPolicy, block TransactionBlock) error {
+	if tx := m.ownTx(txFromContext(ctx)); tx != nil {
+		return m.TransactionCtxTx(ctx, tx, block)
+	}
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 3
+	}
+	backoff := policy.MinBackoff
+	if backoff <= 0 {
+		backoff = 10 * time.Millisecond
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		err := m.begin(ctx, policy.Options, block)
+		if err == nil || !m.isRetryable(err) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("transaction failed after %d attempts: %w", attempt, err)
+		}
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if m.logger != nil {
+			m.logger.Warning(fmt.Sprintf("transaction attempt %d of %d failed, retrying in %s: %v",
+				attempt, maxAttempts, wait, err))
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction failed after %d attempts: %w", attempt, err)
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryable returns true if err, or an error it wraps, is a serialization
+// failure or a deadlock.
+func (m Model) isRetryable(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch m.connection.ErrGetCode(err) {
+		case errCodeSerializationFailure, errCodeDeadlockDetected:
+			return true
+		}
+	}
+	return false
+}
+
+// begin executes the block within a new transaction with the given options.
+func (m Model) begin(ctx context.Context, opts TxOptions, block TransactionBlock) (err error) {
+	switch opts.Isolation {
+	case "", ReadUncommitted, ReadCommitted, RepeatableRead, Serializable:
+	default:
+		return ErrInvalidIsolation
+	}
+	if mode := opts.String(); mode != "" {
+		m.log("BEGIN "+mode, nil, 0)
+	} else {
+		m.log("BEGIN", nil, 0)
+	}
 	var tx Tx
-	tx, err = m.connection.BeginTx(ctx, "", false)
+	tx, err = m.connection.BeginTx(ctx, opts.Isolation, opts.ReadOnly)
 	if err != nil {
 		return
 	}
+	hooks := &txHooks{}
 	defer func() {
 		if r := recover(); r != nil {
 			m.log("ROLLBACK", nil, 0)
 			tx.Rollback(ctx)
-			if rerr, ok := r.(error); ok {
-				err = rerr
-			} else {
-				err = errors.New(fmt.Sprint(r))
-			}
+			err = panicError(r)
 		} else if err != nil {
 			m.log("ROLLBACK", nil, 0)
 			tx.Rollback(ctx)
@@ -66,7 +506,69 @@ func (m Model) TransactionCtx(ctx context.Context, block TransactionBlock) (err
 			m.log("COMMIT", nil, 0)
 			err = tx.Commit(ctx)
 		}
+		if err == nil {
+			err = hookError(nil, m.runAfterCommit(hooks))
+		} else {
+			err = hookError(err, m.runAfterRollback(hooks))
+		}
 	}()
-	err = block(ctx, tx)
+	if mode := opts.String(); mode != "" {
+		if err = m.execTx(ctx, tx, "SET TRANSACTION "+mode); err != nil {
+			return
+		}
+	}
+	err = block(context.WithValue(ctx, txContextKey{}, txContext{tx: tx, hooks: hooks}), tx)
 	return
 }
+
+// savepoint executes the block within a savepoint of tx. The savepoint is
+// released if block returns nil; it is rolled back if block returns an error
+// or panics, leaving the rest of the transaction intact. The hooks of a
+// released savepoint are moved to the parent hooks. If there are no parent
+// hooks (tx was not started by a transaction block), the outcome of the
+// transaction is unknown, so the block's ctx carries no hooks and AfterCommit
+// and AfterRollback return ErrUnknownTransaction.
+func (m Model) savepoint(ctx context.Context, tx Tx, depth int, parent *txHooks, block TransactionBlock) (err error) {
+	name := fmt.Sprintf("sp_%d", depth)
+	if err = m.execTx(ctx, tx, "SAVEPOINT "+name); err != nil {
+		return
+	}
+	var hooks *txHooks
+	if parent != nil {
+		hooks = &txHooks{}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			m.execTx(ctx, tx, "ROLLBACK TO SAVEPOINT "+name)
+			err = panicError(r)
+		} else if err != nil {
+			m.execTx(ctx, tx, "ROLLBACK TO SAVEPOINT "+name)
+		} else {
+			err = m.execTx(ctx, tx, "RELEASE SAVEPOINT "+name)
+		}
+		if hooks == nil {
+			return
+		} else if err != nil {
+			err = hookError(err, m.runAfterRollback(hooks))
+		} else {
+			parent.merge(hooks)
+		}
+	}()
+	err = block(context.WithValue(ctx, txContextKey{}, txContext{tx: tx, depth: depth, hooks: hooks}), tx)
+	return
+}
+
+// execTx executes a transaction control statement in tx and logs it.
+func (m Model) execTx(ctx context.Context, tx Tx, sql string) error {
+	m.log(sql, nil, 0)
+	_, err := tx.ExecContext(ctx, sql)
+	return err
+}
+
+// panicError converts a recovered panic value to an error.
+func panicError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return errors.New(fmt.Sprint(r))
+}
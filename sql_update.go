@@ -1,8 +1,10 @@
 package psql
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -13,7 +15,20 @@ type (
 		*SQL
 		sqlConditions
 		changes          []interface{}
+		from             string
 		outputExpression string
+		manyKey          string
+		manyRows         []Changes
+		scope            string
+		allowAll         bool
+	}
+
+	// Tracker keeps a copy of a struct to update only the fields that have
+	// been changed since. Create instances using Model.Track.
+	Tracker struct {
+		model    Model
+		target   interface{}
+		original reflect.Value
 	}
 )
 
@@ -29,7 +44,8 @@ func (s SQL) AsUpdate(changes ...interface{}) *UpdateSQL {
 }
 
 // Update creates an UPDATE statement with the given field/value changes.
-// Always use Where to specify which rows to update.
+// Always use Where to specify which rows to update. Executing an UPDATE
+// without conditions returns ErrNoConditions, unless AllowAll is called.
 //
 //	// Using field/value pairs
 //	users.Update("Name", "Bob").Where("id = $1", 1).MustExecute()
@@ -37,16 +53,291 @@ func (s SQL) AsUpdate(changes ...interface{}) *UpdateSQL {
 //	// Using Changes from Filter with rows affected count
 //	var count int
 //	users.Update(changes).Where("id = $1", 1).MustExecute(&count)
+//
+// If the model has a version column for optimistic locking (a field tagged
+// with `column:",version"`), it is incremented by every UPDATE. When the
+// changes contain the version value read before, it is used as a condition
+// instead, and Execute returns ErrStaleObject if no rows are updated.
+//
+//	users.Update("Name", "Bob", "LockVersion", user.LockVersion).Where("id = $1", 1).Execute()
+//	// UPDATE users SET name = $2, lock_version = lock_version + 1
+//	// WHERE (id = $1) AND (lock_version = $3)
 func (m Model) Update(lotsOfChanges ...interface{}) *UpdateSQL {
 	return m.NewSQL("").AsUpdate(lotsOfChanges...)
 }
 
+// UpdateMany creates an UPDATE statement that updates many rows with
+// different values at once, by joining the table with a VALUES list. Each row
+// must contain the value of keyField, which identifies the row to update,
+// otherwise the statement returns an error when executed. Values are cast to
+// the types of their columns (see ColumnDataTypes). Fields in JSONB columns are
+// merged into the current values of the row, so they cannot be removed with
+// Remove, and nested keys (see FieldChanges) cannot be changed.
+//
+// If the model has a version column, it is incremented in every row. Rows
+// containing the version are only updated if it matches the version of the
+// row; if any of them does not, Execute returns ErrStaleObject and no rows are
+// updated.
+//
+//	users.UpdateMany("Id", []psql.Changes{
+//		users.Changes(psql.RawChanges{"Id": 1, "Name": "Alice"}),
+//		users.Changes(psql.RawChanges{"Id": 2, "Name": "Bob"}),
+//	}).MustExecute(&count)
+//	// UPDATE users SET name = v.name FROM (VALUES ($1::integer, $2::text),
+//	// ($3::integer, $4::text)) AS v(id, name) WHERE users.id = v.id
+//
+// Rows changing different sets of fields, and rows exceeding the parameter
+// limit of PostgreSQL, are updated by separate statements in a transaction.
+// String and StringValues then join the statements with semicolons and number
+// their parameters in sequence, which is for display only and cannot be
+// executed as one statement. Where adds more conditions for all rows.
+func (m Model) UpdateMany(keyField string, rows []Changes) *UpdateSQL {
+	u := m.Update()
+	u.manyKey = keyField
+	u.manyRows = rows
+	key := m.FieldByName(keyField)
+	if key == nil || key.Jsonb != "" {
+		u.setErr(fmt.Errorf("unknown key field %q", keyField))
+		return u
+	}
+	for i, row := range rows {
+		hasKey := false
+		for field, value := range row {
+			if field.Name == key.Name {
+				hasKey = true
+			} else if _, ok := value.(remove); ok && field.Jsonb != "" {
+				u.setErr(fmt.Errorf("field %s of row %d is stored in JSONB column %s, which cannot be removed by UpdateMany", field.Name, i, field.Jsonb))
+			} else if field.JsonbPath != "" {
+				u.setErr(fmt.Errorf("nested key %s of field %s of row %d cannot be changed by UpdateMany", field.JsonbPath, field.Name, i))
+			}
+		}
+		if !hasKey {
+			u.setErr(fmt.Errorf("row %d has no value of key field %s", i, key.Name))
+		}
+	}
+	return u
+}
+
+// MustUpdateStruct is like UpdateStruct but panics if update operation fails.
+func (m Model) MustUpdateStruct(ctx context.Context, tx Tx, original, modified interface{}) {
+	if err := m.UpdateStruct(ctx, tx, original, modified); err != nil {
+		panic(err)
+	}
+}
+
+// UpdateStruct updates the columns of fields whose values in modified differ
+// from those in original (see Diff), of the row with the primary key value of
+// original. Nothing is executed if there are no changes. ErrInvalidTarget is
+// returned if original or modified is not a struct (or non-nil pointer to a
+// struct) of the model. Tx can be nil.
+//
+// If the model has a version column, the version of original is checked
+// (ErrStaleObject is returned if the row has been updated since), and the
+// version of modified is incremented if it is a pointer.
+//
+//	original := user
+//	user.Name = "Bob"
+//	users.MustUpdateStruct(ctx, nil, original, user)
+//	// UPDATE users SET name = $2 WHERE id = $1
+func (m Model) UpdateStruct(ctx context.Context, tx Tx, original, modified interface{}) error {
+	update, err := m.updateStruct(original, modified)
+	if err != nil || update == nil {
+		return err
+	}
+	if err := update.ExecuteCtxTx(ctx, tx); err != nil {
+		return err
+	}
+	if field, ok := m.versionField(); ok {
+		if rv := reflect.ValueOf(modified); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			incrementVersion(reflect.ValueOf(field.getFieldValueAddrFromStruct(rv.Elem())).Elem())
+		}
+	}
+	return nil
+}
+
+// updateStruct returns the UPDATE statement of UpdateStruct, or nil if there
+// are no changes.
+func (m Model) updateStruct(original, modified interface{}) (*UpdateSQL, error) {
+	changes, err := m.Diff(original, modified)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	orig := addressableStruct(reflect.ValueOf(original))
+	pk, err := m.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+	key := reflect.ValueOf(pk.getFieldValueAddrFromStruct(orig)).Elem().Interface()
+	if field, ok := m.versionField(); ok {
+		changes[field] = reflect.ValueOf(field.getFieldValueAddrFromStruct(orig)).Elem().Interface()
+	}
+	return m.Update(changes).Where(pk.ColumnName+" = $1", key), nil
+}
+
+// incrementVersion adds one to the integer (or pointer to integer) value of
+// a version field.
+func incrementVersion(value reflect.Value) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(value.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(value.Uint() + 1)
+	}
+}
+
+// Track returns a Tracker keeping a copy of the struct that target points to,
+// so that changes made to it later can be saved with Tracker.Update.
+//
+//	var user User
+//	users.Find().Where("id = $1", 1).MustQuery(&user)
+//	tracker := users.Track(&user)
+//	user.Name = "Bob"
+//	tracker.MustUpdate(ctx, nil) // UPDATE users SET name = $2 WHERE id = $1
+func (m Model) Track(target interface{}) *Tracker {
+	t := &Tracker{
+		model:  m,
+		target: target,
+	}
+	t.snapshot()
+	return t
+}
+
+// Changes returns the Changes made to the tracked struct since Track or the
+// last Update.
+func (t *Tracker) Changes() Changes {
+	if !t.original.IsValid() {
+		return Changes{}
+	}
+	changes, err := t.model.Diff(t.original.Interface(), t.target)
+	if err != nil {
+		return Changes{}
+	}
+	return changes
+}
+
+// MustUpdate is like Update but panics if update operation fails.
+func (t *Tracker) MustUpdate(ctx context.Context, tx Tx) {
+	if err := t.Update(ctx, tx); err != nil {
+		panic(err)
+	}
+}
+
+// Update saves the changes made to the tracked struct (see UpdateStruct).
+// The tracked copy is refreshed after a successful update. Tx can be nil.
+func (t *Tracker) Update(ctx context.Context, tx Tx) error {
+	rv := reflect.ValueOf(t.target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrMustBePointer
+	}
+	if !t.original.IsValid() {
+		return ErrInvalidTarget
+	}
+	if err := t.model.UpdateStruct(ctx, tx, t.original.Interface(), t.target); err != nil {
+		return err
+	}
+	t.snapshot()
+	return nil
+}
+
+// snapshot keeps a copy of the tracked struct. Slices and maps of fields are
+// copied too, so that changing their elements is detected.
+func (t *Tracker) snapshot() {
+	rv := reflect.ValueOf(t.target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || !t.model.isStructType(rv.Type()) {
+		return
+	}
+	original := reflect.New(rv.Elem().Type()).Elem()
+	original.Set(rv.Elem())
+	for _, field := range t.model.modelFields {
+		value := reflect.ValueOf(field.getFieldValueAddrFromStruct(original)).Elem()
+		switch value.Kind() {
+		case reflect.Slice:
+			if !value.IsNil() {
+				value.Set(reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0, value.Len()), value))
+			}
+		case reflect.Map:
+			if !value.IsNil() {
+				m := reflect.MakeMapWithSize(value.Type(), value.Len())
+				iter := value.MapRange()
+				for iter.Next() {
+					m.SetMapIndex(iter.Key(), iter.Value())
+				}
+				value.Set(m)
+			}
+		}
+	}
+	t.original = original
+}
+
 // Returning adds a RETURNING clause to retrieve values from updated rows.
 func (s *UpdateSQL) Returning(expressions ...string) *UpdateSQL {
 	s.outputExpression = strings.Join(expressions, ", ")
 	return s
 }
 
+// ReturningModel adds a RETURNING clause of all columns of the Model (see
+// Columns), so that the updated rows can be scanned into the Model's struct
+// (or a slice of it) with Query, like the results of Find.
+//
+//	var updated []User
+//	users.Update("Status", "active").Where("status = $1", "new").ReturningModel().MustQuery(&updated)
+func (s *UpdateSQL) ReturningModel() *UpdateSQL {
+	return s.Returning(s.model.AddTableName(s.model.Columns()...)...)
+}
+
+// AllowAll allows the statement to update all rows of the table when it has
+// no WHERE conditions.
+//
+//	users.Update("Status", "active").AllowAll().MustExecute()
+func (s *UpdateSQL) AllowAll() *UpdateSQL {
+	s.allowAll = true
+	return s
+}
+
+// MaxAffected makes Execute fail with ErrUnexpectedRowsAffected and roll back
+// the update if it affects more than n rows.
+func (s *UpdateSQL) MaxAffected(n int64) *UpdateSQL {
+	s.SQL.MaxAffected(n)
+	return s
+}
+
+func (s *UpdateSQL) missingConditions() bool {
+	return s.sql == "" && s.manyRows == nil && len(s.conditions) == 0 && !s.allowAll
+}
+
+// From appends items to the FROM clause, so that columns of other tables can
+// be used in the WHERE condition and the SET expressions.
+//
+//	users.Update("Plan", psql.String("accounts.plan")).From("accounts").
+//		Where("users.account_id = accounts.id")
+//	// UPDATE users SET plan = accounts.plan FROM accounts WHERE users.account_id = accounts.id
+func (s *UpdateSQL) From(items ...string) *UpdateSQL {
+	if s.from != "" {
+		s.from += ", "
+	}
+	s.from += strings.Join(items, ", ")
+	return s
+}
+
+// FROM appends a subquery from another SelectSQL to the FROM clause with the
+// given alias. The arguments of the subquery are merged and renumbered.
+//
+//	totals := orders.Select("user_id", "SUM(amount) AS total").Where("status = $1", "paid").GroupBy("user_id")
+//	users.Update("Total", psql.String("t.total")).FROM("t", totals).Where("users.id = t.user_id")
+//	// UPDATE users SET total = t.total FROM (SELECT user_id, SUM(amount) AS total
+//	// FROM orders WHERE status = $1 GROUP BY user_id) AS t WHERE users.id = t.user_id
+func (s *UpdateSQL) FROM(alias string, query *SelectSQL) *UpdateSQL {
+	s.From("(" + shiftParameters(query.String(), len(s.args)) + ") AS " + alias)
+	s.args = append(s.args, query.args...)
+	return s
+}
+
 // Where adds a WHERE condition to the UPDATE statement. Use $1, $2 for
 // positional parameters, or $? which is auto-replaced when a single argument
 // is provided.
@@ -74,8 +365,13 @@ func (s *UpdateSQL) WHERE(args ...interface{}) *UpdateSQL {
 		if column == "" || operator == "" {
 			continue
 		}
-		s.args = append(s.args, args[i*3+2])
-		s.conditions = append(s.conditions, fmt.Sprintf("%s %s $%d", s.model.ToColumnName(column), operator, len(s.args)))
+		condition, arg, err := s.model.tupleCondition(column, operator, args[i*3+2], len(s.args)+1)
+		if err != nil {
+			s.setErr(err)
+			continue
+		}
+		s.args = append(s.args, arg)
+		s.conditions = append(s.conditions, condition)
 	}
 	return s
 }
@@ -114,14 +410,25 @@ func (s *UpdateSQL) String() string {
 }
 
 func (s *UpdateSQL) StringValues() (string, []interface{}) {
+	if s.manyRows != nil {
+		return s.manyStringValues()
+	}
 	fields := []string{}
 	fieldsIndex := map[string]int{}
 	values := []interface{}{}
 	values = append(values, s.args...)
 	jsonbFields := map[string]Changes{}
 	i := len(s.args) + 1
+	versionField, version, checkVersion := s.lockVersion()
+	versionSet := false
 	for _, changes := range s.model.getChanges(s.changes) {
 		for field, value := range changes {
+			if field.Version {
+				if isPlainValue(value) {
+					continue
+				}
+				versionSet = true
+			}
 			if field.Jsonb != "" {
 				if _, ok := jsonbFields[field.Jsonb]; !ok {
 					jsonbFields[field.Jsonb] = Changes{}
@@ -133,6 +440,10 @@ func (s *UpdateSQL) StringValues() (string, []interface{}) {
 				fields = append(fields, fmt.Sprintf("%s = %s", field.ColumnName, s))
 				continue
 			}
+			if _, ok := value.(remove); ok {
+				fields = append(fields, field.ColumnName+" = NULL")
+				continue
+			}
 			if idx, ok := fieldsIndex[field.Name]; ok { // prevent duplication
 				switch v := value.(type) {
 				case stringWithArg:
@@ -161,19 +472,46 @@ func (s *UpdateSQL) StringValues() (string, []interface{}) {
 	}
 	for jsonbField, changes := range jsonbFields {
 		var field = fmt.Sprintf("COALESCE(%s, '{}'::jsonb)", jsonbField)
-		for f, value := range changes {
+		sorted := make([]Field, 0, len(changes))
+		for f := range changes {
+			sorted = append(sorted, f)
+		}
+		sortJsonbFields(sorted)
+		// create missing parents of nested keys from the current values
+		parents := map[string]bool{}
+		for _, f := range sorted {
+			path := f.jsonbPath()
+			for n := 1; n < len(path); n++ {
+				parent := "{" + strings.Join(path[:n], ",") + "}"
+				if parents[parent] {
+					continue
+				}
+				parents[parent] = true
+				field = fmt.Sprintf("jsonb_set(%s, '%s', COALESCE(NULLIF(%s #> '%s', 'null'::jsonb), '{}'::jsonb))",
+					field, parent, jsonbField, parent)
+			}
+		}
+		for _, f := range sorted {
+			value := changes[f]
+			path := "{" + strings.Join(f.jsonbPath(), ",") + "}"
+			createMissing := ""
+			if f.JsonbPath != "" {
+				createMissing = ", true"
+			}
 			if s, ok := value.(String); ok {
-				field = fmt.Sprintf("jsonb_set(%s, '{%s}', %s)", field, f.ColumnName, s)
+				field = fmt.Sprintf("jsonb_set(%s, '%s', %s%s)", field, path, s, createMissing)
 				continue
 			}
 			switch v := value.(type) {
+			case remove:
+				field = fmt.Sprintf("%s #- '%s'", field, path)
 			case stringWithArg:
 				str := strings.Replace(v.str, "$?", fmt.Sprintf("$%d", i), -1)
-				field = fmt.Sprintf("jsonb_set(%s, '{%s}', %s)", field, f.ColumnName, str)
+				field = fmt.Sprintf("jsonb_set(%s, '%s', %s%s)", field, path, str, createMissing)
 				values = append(values, v.arg)
 				i += 1
 			default:
-				field = fmt.Sprintf("jsonb_set(%s, '{%s}', $%d)", field, f.ColumnName, i)
+				field = fmt.Sprintf("jsonb_set(%s, '%s', $%d%s)", field, path, i, createMissing)
 				j, _ := json.Marshal(v)
 				values = append(values, string(j))
 				i += 1
@@ -181,6 +519,22 @@ func (s *UpdateSQL) StringValues() (string, []interface{}) {
 		}
 		fields = append(fields, jsonbField+" = "+field)
 	}
+	conditions := s.sqlConditions
+	if s.scope != "" && s.sql == "" {
+		conditions.conditions = append(conditions.conditions[:len(conditions.conditions):len(conditions.conditions)], s.scope)
+	}
+	if versionField.Version && s.sql == "" {
+		if !versionSet && (len(fields) > 0 || checkVersion) {
+			column := versionField.ColumnName
+			fields = append(fields, column+" = "+column+" + 1")
+		}
+		if checkVersion {
+			conditions.conditions = append(conditions.conditions[:len(conditions.conditions):len(conditions.conditions)],
+				fmt.Sprintf("%s = $%d", versionField.ColumnName, i))
+			values = append(values, version)
+			i += 1
+		}
+	}
 	var sql string
 	if s.sql != "" {
 		sql = s.sql
@@ -193,10 +547,266 @@ func (s *UpdateSQL) StringValues() (string, []interface{}) {
 		sql = "UPDATE " + s.model.tableName + " SET " + strings.Join(fields, ", ")
 	}
 	if sql != "" {
-		sql += s.where()
+		if s.from != "" {
+			sql += " FROM " + s.from
+		}
+		sql += conditions.where()
 		if s.outputExpression != "" {
 			sql += " RETURNING " + s.outputExpression
 		}
 	}
 	return s.model.convertValues(sql, values)
 }
+
+// lockVersion returns the version field of the model, and the version value
+// of changes if any.
+func (s *UpdateSQL) lockVersion() (field Field, version interface{}, ok bool) {
+	field, found := s.model.versionField()
+	if !found {
+		return
+	}
+	for _, changes := range s.model.getChanges(s.changes) {
+		for f, value := range changes {
+			if f.Version && isPlainValue(value) {
+				version, ok = value, true
+			}
+		}
+	}
+	return
+}
+
+func (s *UpdateSQL) checksVersion() bool {
+	if s.sql != "" {
+		return false
+	}
+	_, _, ok := s.lockVersion()
+	return ok
+}
+
+// isPlainValue returns false for raw SQL expressions created by String and
+// StringWithArg.
+func isPlainValue(value interface{}) bool {
+	switch value.(type) {
+	case String, stringWithArg:
+		return false
+	}
+	return true
+}
+
+// manyStringValues renders the statements of UpdateMany, joined with
+// semicolons if there is more than one, for display only.
+func (s *UpdateSQL) manyStringValues() (string, []interface{}) {
+	stmts := s.manyStatements(0)
+	sqls := []string{}
+	values := []interface{}{}
+	for _, stmt := range stmts {
+		sqls = append(sqls, shiftParameters(stmt.sql, len(values)))
+		values = append(values, stmt.values...)
+	}
+	return s.model.convertValues(strings.Join(sqls, "; "), values)
+}
+
+// batches splits an UpdateMany into statements of rows changing the same
+// fields, each within the parameter limit of PostgreSQL.
+func (s *UpdateSQL) batches() []statement {
+	if s.manyRows == nil {
+		return nil
+	}
+	stmts := s.manyStatements(maxParameters)
+	for i, stmt := range stmts {
+		stmts[i].sql, stmts[i].values = s.model.convertValues(stmt.sql, stmt.values)
+	}
+	return stmts
+}
+
+// manyStatements renders the UPDATE statements of UpdateMany, one for each
+// set of changed fields in the order they first appear, split into statements
+// of at most maxParams parameters each. Zero maxParams means no limit. Rows
+// without the key, reported as an error by UpdateMany, are left out.
+func (s *UpdateSQL) manyStatements(maxParams int) (stmts []statement) {
+	key := s.model.FieldByName(s.manyKey)
+	if key == nil || key.Jsonb != "" {
+		return
+	}
+	var order []string
+	groups := map[string][]Changes{}
+	groupFields := map[string][]Field{}
+	groupJsonb := map[string][]string{}
+	for _, row := range s.manyRows {
+		hasKey := false
+		for field := range row {
+			if field.Name == key.Name {
+				hasKey = true
+			}
+		}
+		if !hasKey {
+			continue
+		}
+		fields, jsonbColumns := s.manyColumns(row, *key)
+		names := []string{}
+		for _, field := range fields {
+			names = append(names, field.ColumnName)
+		}
+		names = append(names, jsonbColumns...)
+		if len(names) == 0 {
+			continue
+		}
+		group := strings.Join(names, ",")
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+			groupFields[group] = fields
+			groupJsonb[group] = jsonbColumns
+		}
+		groups[group] = append(groups[group], row)
+	}
+	dataTypes := s.model.ColumnDataTypes()
+	for _, group := range order {
+		stmts = append(stmts, s.manyGroupStatements(groups[group], *key,
+			groupFields[group], groupJsonb[group], dataTypes, maxParams)...)
+	}
+	return
+}
+
+// manyColumns returns the regular fields (other than the key) and the JSONB
+// columns changed by the row, in the order of the model's fields.
+func (s *UpdateSQL) manyColumns(row Changes, key Field) (fields []Field, jsonbColumns []string) {
+	names := map[string]bool{}
+	jsonbs := map[string]bool{}
+	for field, value := range row {
+		if field.Jsonb != "" {
+			if _, ok := value.(remove); !ok {
+				jsonbs[field.Jsonb] = true
+			}
+		} else if field.Name != key.Name {
+			names[field.Name] = true
+		}
+	}
+	for _, field := range s.model.modelFields {
+		if field.Jsonb == "" && names[field.Name] {
+			fields = append(fields, field)
+		}
+	}
+	for _, column := range s.model.jsonbColumns {
+		if jsonbs[column] {
+			jsonbColumns = append(jsonbColumns, column)
+		}
+	}
+	return
+}
+
+// manyGroupStatements renders the UPDATE statements of rows changing the same
+// fields.
+func (s *UpdateSQL) manyGroupStatements(rows []Changes, key Field, fields []Field, jsonbColumns []string,
+	dataTypes map[string]string, maxParams int) (stmts []statement) {
+	table := s.model.tableName
+	columns := []string{key.ColumnName}
+	sets := []string{}
+	for _, field := range fields {
+		columns = append(columns, field.ColumnName)
+		sets = append(sets, fmt.Sprintf("%s = v.%s", field.ColumnName, field.ColumnName))
+	}
+	for _, column := range jsonbColumns {
+		columns = append(columns, column)
+		sets = append(sets, fmt.Sprintf("%s = COALESCE(%s.%s, '{}'::jsonb) || v.%s", column, table, column, column))
+	}
+	conditions := sqlConditions{
+		conditions: []string{fmt.Sprintf("%s.%s = v.%s", table, key.ColumnName, key.ColumnName)},
+	}
+	versioned := false
+	if field, ok := s.model.versionField(); ok {
+		for i, f := range fields {
+			if f.Name == field.Name {
+				versioned = true
+				sets = append(sets[:i:i], sets[i+1:]...)
+			}
+		}
+		sets = append(sets, fmt.Sprintf("%s = %s.%s + 1", field.ColumnName, table, field.ColumnName))
+		if versioned {
+			conditions.conditions = append(conditions.conditions,
+				fmt.Sprintf("%s.%s = v.%s", table, field.ColumnName, field.ColumnName))
+		}
+	}
+	conditions.conditions = append(conditions.conditions, s.conditions...)
+	types := []string{castType(dataTypes[key.ColumnName])}
+	for _, field := range fields {
+		types = append(types, castType(dataTypes[field.ColumnName]))
+	}
+	var tuples []string
+	values := append([]interface{}{}, s.args...)
+	flush := func() {
+		sql := "UPDATE " + table + " SET " + strings.Join(sets, ", ") +
+			" FROM (VALUES " + strings.Join(tuples, ", ") + ") AS v(" + strings.Join(columns, ", ") + ")"
+		if s.from != "" {
+			sql += ", " + s.from
+		}
+		sql += conditions.where()
+		if s.outputExpression != "" {
+			sql += " RETURNING " + s.outputExpression
+		}
+		stmt := statement{sql: sql, values: values}
+		if versioned {
+			stmt.rows = int64(len(tuples))
+		}
+		stmts = append(stmts, stmt)
+	}
+	for _, row := range rows {
+		tuple, vals := manyRowValues(row, key, fields, jsonbColumns, types, len(values)+1)
+		if maxParams > 0 && len(tuples) > 0 && len(values)+len(vals) > maxParams {
+			flush()
+			tuples, values = nil, append([]interface{}{}, s.args...)
+			tuple, vals = manyRowValues(row, key, fields, jsonbColumns, types, len(values)+1)
+		}
+		tuples = append(tuples, tuple)
+		values = append(values, vals...)
+	}
+	if len(tuples) > 0 {
+		flush()
+	}
+	return
+}
+
+// manyRowValues renders the VALUES tuple of a row of UpdateMany, numbering
+// parameters from start and casting them to types, and returns the tuple and
+// its parameter values.
+func manyRowValues(row Changes, key Field, fields []Field, jsonbColumns []string, types []string, start int) (string, []interface{}) {
+	byName := map[string]interface{}{}
+	jsonbValues := map[string]Changes{}
+	for field, value := range row {
+		if field.Jsonb == "" {
+			byName[field.Name] = value
+			continue
+		}
+		if _, ok := jsonbValues[field.Jsonb]; !ok {
+			jsonbValues[field.Jsonb] = Changes{}
+		}
+		jsonbValues[field.Jsonb][field] = value
+	}
+	items := []string{}
+	values := []interface{}{}
+	cast := func(placeholder, dataType string) string {
+		if dataType == "" {
+			return placeholder
+		}
+		return placeholder + "::" + dataType
+	}
+	for i, field := range append([]Field{key}, fields...) {
+		switch v := byName[field.Name].(type) {
+		case String:
+			items = append(items, string(v))
+		case remove:
+			items = append(items, cast("NULL", types[i]))
+		case stringWithArg:
+			items = append(items, strings.Replace(v.str, "$?", fmt.Sprintf("$%d", start+len(values)), -1))
+			values = append(values, v.arg)
+		default:
+			items = append(items, cast(fmt.Sprintf("$%d", start+len(values)), types[i]))
+			values = append(values, v)
+		}
+	}
+	for _, column := range jsonbColumns {
+		j, _ := json.Marshal(jsonbObject(jsonbValues[column]))
+		items = append(items, cast(fmt.Sprintf("$%d", start+len(values)), "jsonb"))
+		values = append(values, string(j))
+	}
+	return "(" + strings.Join(items, ", ") + ")", values
+}
@@ -321,7 +321,7 @@ func testCRUDOperations(t *testing.T, conn db.DB) {
 	t.Run("Update", func(t *testing.T) {
 		var rowsAffected int
 		model.MustTransaction(func(ctx context.Context, tx db.Tx) error {
-			model.Update("Status", "updated").MustExecuteCtxTx(ctx, tx, &rowsAffected)
+			model.Update("Status", "updated").AllowAll().MustExecuteCtxTx(ctx, tx, &rowsAffected)
 			return nil
 		})
 		if rowsAffected != 2 {
@@ -338,7 +338,7 @@ func testCRUDOperations(t *testing.T, conn db.DB) {
 	// Test Delete
 	t.Run("Delete", func(t *testing.T) {
 		var rowsDeleted int
-		err := model.Delete().Execute(&rowsDeleted)
+		err := model.Delete().AllowAll().Execute(&rowsDeleted)
 		if err != nil {
 			t.Fatalf("Delete failed: %v", err)
 		}
@@ -424,6 +424,76 @@ func testTransactionOperations(t *testing.T, conn db.DB) {
 			t.Errorf("count = %d, want 1 (should not have increased)", count)
 		}
 	})
+
+	t.Run("NestedTransaction", func(t *testing.T) {
+		model.MustTransaction(func(ctx context.Context, tx db.Tx) error {
+			model.Insert(model.Changes(psql.RawChanges{"Status": "tx4"})).MustExecuteCtxTx(ctx, tx)
+			model.MustTransactionCtx(ctx, func(ctx context.Context, tx db.Tx) error {
+				model.Insert(model.Changes(psql.RawChanges{"Status": "tx5"})).MustExecuteCtxTx(ctx, tx)
+				return nil
+			})
+			err := model.TransactionCtx(ctx, func(ctx context.Context, tx db.Tx) error {
+				model.Insert(model.Changes(psql.RawChanges{"Status": "tx6"})).MustExecuteCtxTx(ctx, tx)
+				return errors.New("rollback")
+			})
+			if err == nil || err.Error() != "rollback" {
+				t.Errorf("err = %v, want 'rollback'", err)
+			}
+			err = model.TransactionCtxTx(ctx, tx, func(ctx context.Context, tx db.Tx) error {
+				model.NewSQL("SELECT 1/0").MustExecuteCtxTx(ctx, tx)
+				return nil
+			})
+			if err == nil {
+				t.Error("err = nil, want division by zero")
+			}
+			return nil
+		})
+
+		var statuses []string
+		model.Select("status").Where("status LIKE 'tx%'").OrderBy("id").MustQuery(&statuses)
+		if strings.Join(statuses, ",") != "tx1,tx4,tx5" {
+			t.Errorf("statuses = %v, want [tx1 tx4 tx5]", statuses)
+		}
+	})
+
+	t.Run("ContextTransaction", func(t *testing.T) {
+		model.TransactionCtx(context.Background(), func(ctx context.Context, _ db.Tx) error {
+			model.Insert(model.Changes(psql.RawChanges{"Status": "tx7"})).MustExecuteCtx(ctx)
+			if n := model.Where("status = 'tx7'").MustCountCtx(ctx); n != 1 {
+				t.Errorf("count in transaction = %d, want 1", n)
+			}
+			if n := model.Where("status = 'tx7'").MustCountCtx(psql.WithoutTx(ctx)); n != 0 {
+				t.Errorf("count outside transaction = %d, want 0", n)
+			}
+			return errors.New("rollback")
+		})
+		if n := model.Where("status = 'tx7'").MustCount(); n != 0 {
+			t.Errorf("count = %d, want 0", n)
+		}
+	})
+
+	t.Run("TransactionOptions", func(t *testing.T) {
+		opts := psql.TxOptions{Isolation: psql.Serializable, ReadOnly: true, Deferrable: true}
+		model.MustTransactionOptsCtx(context.Background(), opts, func(ctx context.Context, tx db.Tx) error {
+			var level string
+			model.NewSQL("SHOW transaction_isolation").MustQueryRowCtx(ctx, &level)
+			if level != "serializable" {
+				t.Errorf("transaction_isolation = %q, want serializable", level)
+			}
+			var deferrable string
+			model.NewSQL("SHOW transaction_deferrable").MustQueryRowCtx(ctx, &deferrable)
+			if deferrable != "on" {
+				t.Errorf("transaction_deferrable = %q, want on", deferrable)
+			}
+			return nil
+		})
+		err := model.TransactionOptsCtx(context.Background(), psql.TxOptions{ReadOnly: true}, func(ctx context.Context, tx db.Tx) error {
+			return model.Insert(model.Changes(psql.RawChanges{"Status": "tx8"})).ExecuteCtx(ctx)
+		})
+		if err == nil {
+			t.Error("err = nil, want read-only transaction error")
+		}
+	})
 }
 
 func TestQueryTimeout(t *testing.T) {
@@ -1,12 +1,18 @@
 package psql_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gopsql/db"
 	"github.com/gopsql/gopg"
+	"github.com/gopsql/logger"
 	"github.com/gopsql/pgx"
 	"github.com/gopsql/pq"
 	"github.com/gopsql/psql"
@@ -346,3 +352,693 @@ func TestQueryErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryOnlyFields(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testOrder struct {
+				__TABLE_NAME__ string `orders`
+
+				Id       int
+				Status   string
+				Password string
+				Theme    string `jsonb:"meta"`
+				Locale   string `jsonb:"meta"`
+			}
+
+			model := psql.NewModel(testOrder{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			model.Insert(
+				"Status", "active",
+				"Password", "secret",
+				"Theme", "dark",
+				"Locale", "en",
+			).MustExecute()
+
+			t.Run("Only", func(t *testing.T) {
+				var order testOrder
+				model.Find(psql.Only("Status", "Meta.Theme")).MustQuery(&order)
+				if order.Status != "active" || order.Theme != "dark" {
+					t.Errorf("order = %+v, want Status and Theme", order)
+				}
+				if order.Id != 0 || order.Password != "" || order.Locale != "" {
+					t.Errorf("order = %+v, want other fields empty", order)
+				}
+			})
+
+			t.Run("Except", func(t *testing.T) {
+				var orders []testOrder
+				model.Find(psql.Except("Password")).MustQuery(&orders)
+				if len(orders) != 1 {
+					t.Fatalf("len = %d, want 1", len(orders))
+				}
+				if orders[0].Password != "" || orders[0].Locale != "en" {
+					t.Errorf("orders[0] = %+v", orders[0])
+				}
+			})
+		})
+	}
+}
+
+func TestQueryBulkInsert(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testOrder struct {
+				__TABLE_NAME__ string `orders`
+
+				Id     int
+				Status string
+				Theme  string `jsonb:"meta"`
+			}
+
+			model := psql.NewModel(testOrder{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			var ids []int
+			model.Insert([]testOrder{
+				{Status: "a", Theme: "dark"},
+				{Status: "b"},
+				{Status: "c", Theme: "light"},
+			}).Returning("id").MustQuery(&ids)
+			if len(ids) != 3 {
+				t.Fatalf("len(ids) = %d, want 3", len(ids))
+			}
+
+			var orders []testOrder
+			model.Find().OrderBy("id").MustQuery(&orders)
+			if len(orders) != 3 || orders[0].Status != "a" || orders[2].Theme != "light" {
+				t.Errorf("orders = %+v", orders)
+			}
+
+			rows := make([]psql.Changes, 40000)
+			for i := range rows {
+				rows[i] = model.FieldChanges(psql.RawChanges{"Status": "x", "Theme": "y"})
+			}
+			var rowsAffected int
+			model.Insert(rows).MustExecute(&rowsAffected)
+			if rowsAffected != len(rows) {
+				t.Errorf("rowsAffected = %d, want %d", rowsAffected, len(rows))
+			}
+		})
+	}
+}
+
+// copyLogger records the statements logged by a model.
+type copyLogger struct {
+	logger.Logger
+	lines []string
+}
+
+func (l *copyLogger) Debug(args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(args...))
+}
+
+func (l *copyLogger) copied() bool {
+	for _, line := range l.lines {
+		if strings.Contains(line, "COPY orders") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestQueryCopyFrom(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testOrder struct {
+				__TABLE_NAME__ string `orders`
+
+				Id     int
+				Status string
+				Theme  string `jsonb:"meta"`
+			}
+
+			log := &copyLogger{}
+			model := psql.NewModel(testOrder{}, conn, log)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			ch := make(chan testOrder)
+			go func() {
+				defer close(ch)
+				for i := 0; i < 2500; i++ {
+					ch <- testOrder{Status: "new", Theme: "dark"}
+				}
+			}()
+			n := model.MustCopyFrom(context.Background(), nil, ch)
+			if n != 2500 {
+				t.Errorf("n = %d, want 2500", n)
+			}
+			if count := model.MustCount(); count != 2500 {
+				t.Errorf("count = %d, want 2500", count)
+			}
+			var order testOrder
+			model.Find().OrderBy("id").MustQuery(&order)
+			if order.Id != 1 || order.Theme != "dark" {
+				t.Errorf("order = %+v", order)
+			}
+			_, isGopg := conn.(*gopg.DB)
+			if copied := log.copied(); copied == isGopg {
+				t.Errorf("COPY used = %v, want %v", copied, !isGopg)
+			}
+
+			n = model.MustCopyFrom(context.Background(), nil, []testOrder{{Id: 5000, Status: "explicit"}})
+			if n != 1 {
+				t.Errorf("n = %d, want 1", n)
+			}
+			if !model.Where("id = 5000 AND status = 'explicit'").MustExists() {
+				t.Error("row with explicit id not copied")
+			}
+		})
+	}
+}
+
+func TestQueryInsertStruct(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testOrder struct {
+				__TABLE_NAME__ string `orders`
+
+				Id        int
+				Status    string
+				Theme     string `jsonb:"meta"`
+				CreatedAt time.Time
+			}
+
+			model := psql.NewModel(testOrder{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			order := testOrder{Status: "new", Theme: "dark"}
+			model.MustInsertStruct(context.Background(), nil, &order)
+			if order.Id != 1 || order.CreatedAt.IsZero() || order.Theme != "dark" {
+				t.Errorf("order = %+v", order)
+			}
+
+			createdAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+			dated := testOrder{Status: "old", CreatedAt: createdAt}
+			model.MustInsertStruct(context.Background(), nil, &dated)
+			if dated.Id != 2 || !dated.CreatedAt.Equal(createdAt) {
+				t.Errorf("dated = %+v", dated)
+			}
+
+			var empty testOrder
+			model.MustInsertStruct(context.Background(), nil, &empty)
+			if empty.Id != 3 {
+				t.Errorf("empty.Id = %d, want 3", empty.Id)
+			}
+		})
+	}
+}
+
+func TestQueryUpsertWhere(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testOrder struct {
+				__TABLE_NAME__ string `orders`
+
+				Id      int
+				Status  string
+				Version int
+			}
+
+			model := psql.NewModel(testOrder{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			model.Insert("Status", "new", "Version", 2).MustExecute()
+
+			upsert := func(status string, version int) (n int) {
+				model.Insert("Id", 1, "Status", status, "Version", version).
+					OnConflictConstraint("orders_pkey").DoUpdateAll().
+					Where("orders.version < EXCLUDED.version").MustExecute(&n)
+				return
+			}
+			if n := upsert("old", 1); n != 0 {
+				t.Errorf("older upsert affected %d rows, want 0", n)
+			}
+			if n := upsert("newer", 3); n != 1 {
+				t.Errorf("newer upsert affected %d rows, want 1", n)
+			}
+			var status string
+			model.Select("status").MustQueryRow(&status)
+			if status != "newer" {
+				t.Errorf("status = %q, want newer", status)
+			}
+		})
+	}
+}
+
+func TestQueryOptimisticLocking(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testOrder struct {
+				__TABLE_NAME__ string `orders`
+
+				Id          int
+				Status      string
+				LockVersion int `column:",version"`
+			}
+
+			model := psql.NewModel(testOrder{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			var order testOrder
+			model.MustInsertStruct(context.Background(), nil, &order)
+
+			first, second := order, order
+			first.Status = "first"
+			model.MustUpdateStruct(context.Background(), nil, order, &first)
+			if first.LockVersion != 1 {
+				t.Errorf("first.LockVersion = %d, want 1", first.LockVersion)
+			}
+
+			second.Status = "second"
+			err := model.UpdateStruct(context.Background(), nil, order, &second)
+			if err != psql.ErrStaleObject {
+				t.Errorf("err = %v, want ErrStaleObject", err)
+			}
+		})
+	}
+}
+
+func TestQueryNestedJsonb(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type settings struct {
+				Theme  string `json:"theme"`
+				Locale string `json:"locale"`
+			}
+			type testOrder struct {
+				__TABLE_NAME__ string `orders`
+
+				Id       int
+				Picture  string    `jsonb:"meta"`
+				Settings *settings `jsonb:"meta"`
+			}
+
+			model := psql.NewModel(testOrder{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			model.Insert("Picture", "a.jpg").MustExecute()
+			model.Update(model.FieldChanges(psql.RawChanges{"Settings.Theme": "dark"})).AllowAll().MustExecute()
+			model.Update(model.FieldChanges(psql.RawChanges{"Settings.Locale": "en"})).AllowAll().MustExecute()
+			model.Update("Picture", psql.Remove).AllowAll().MustExecute()
+
+			var order testOrder
+			model.Find().MustQuery(&order)
+			if order.Picture != "" || order.Settings == nil ||
+				order.Settings.Theme != "dark" || order.Settings.Locale != "en" {
+				t.Errorf("order = %+v, settings = %+v", order, order.Settings)
+			}
+		})
+	}
+}
+
+func TestQueryUpdateMany(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testProduct struct {
+				__TABLE_NAME__ string `products`
+
+				Id    int
+				Name  string
+				Price float64
+				Color string `jsonb:"meta"`
+				Size  string `jsonb:"meta"`
+			}
+
+			model := psql.NewModel(testProduct{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			model.Insert([]testProduct{
+				{Name: "a", Price: 1, Color: "red", Size: "S"},
+				{Name: "b", Price: 2, Color: "blue", Size: "M"},
+				{Name: "c", Price: 3, Color: "green", Size: "L"},
+			}).MustExecute()
+
+			var rowsAffected int
+			model.UpdateMany("Id", []psql.Changes{
+				model.Changes(psql.RawChanges{"Id": 1, "Price": 1.5, "Color": "black"}),
+				model.Changes(psql.RawChanges{"Id": 2, "Price": 2.5, "Color": "white"}),
+				model.Changes(psql.RawChanges{"Id": 3, "Name": "C"}),
+			}).MustExecute(&rowsAffected)
+			if rowsAffected != 3 {
+				t.Errorf("rowsAffected = %d, want 3", rowsAffected)
+			}
+
+			var products []testProduct
+			model.Find().OrderBy("id").MustQuery(&products)
+			want := []testProduct{
+				{__TABLE_NAME__: "products", Id: 1, Name: "a", Price: 1.5, Color: "black", Size: "S"},
+				{__TABLE_NAME__: "products", Id: 2, Name: "b", Price: 2.5, Color: "white", Size: "M"},
+				{__TABLE_NAME__: "products", Id: 3, Name: "C", Price: 3, Color: "green", Size: "L"},
+			}
+			if !reflect.DeepEqual(products, want) {
+				t.Errorf("products = %+v, want %+v", products, want)
+			}
+
+			var names []string
+			model.UpdateMany("Id", []psql.Changes{
+				model.Changes(psql.RawChanges{"Id": 1, "Price": 10}),
+				model.Changes(psql.RawChanges{"Id": 2, "Price": 20}),
+			}).Where("products.price < $1", 2).Returning("products.name").MustQuery(&names)
+			if !reflect.DeepEqual(names, []string{"a"}) {
+				t.Errorf("names = %v, want [a]", names)
+			}
+		})
+	}
+}
+
+func TestQuerySoftDelete(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testPost struct {
+				__TABLE_NAME__ string `posts`
+
+				Id        int
+				Title     string
+				DeletedAt *time.Time `column:",softdelete"`
+			}
+
+			model := psql.NewModel(testPost{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			model.Insert([]testPost{{Title: "a"}, {Title: "b"}, {Title: "c"}}).MustExecute()
+
+			var rowsAffected int
+			model.Delete().Where("title = $1", "a").MustExecute(&rowsAffected)
+			if rowsAffected != 1 {
+				t.Errorf("rowsAffected = %d, want 1", rowsAffected)
+			}
+			model.Delete().Where("title = $1", "a").MustExecute(&rowsAffected)
+			if rowsAffected != 0 {
+				t.Errorf("rowsAffected = %d, want 0", rowsAffected)
+			}
+
+			if count := model.MustCount(); count != 2 {
+				t.Errorf("Count() = %d, want 2", count)
+			}
+			if model.Where("title = $1", "a").MustExists() {
+				t.Error("deleted post exists")
+			}
+			if count := model.Select().Unscoped().MustCount(); count != 3 {
+				t.Errorf("Unscoped().Count() = %d, want 3", count)
+			}
+			var deleted []testPost
+			model.Find().OnlyDeleted().MustQuery(&deleted)
+			if len(deleted) != 1 || deleted[0].Title != "a" || deleted[0].DeletedAt == nil {
+				t.Errorf("deleted = %+v", deleted)
+			}
+
+			model.Restore().Where("title = $1", "a").MustExecute()
+			if count := model.MustCount(); count != 3 {
+				t.Errorf("Count() after Restore = %d, want 3", count)
+			}
+
+			model.Delete().HardDelete().Where("title = $1", "b").MustExecute()
+			if count := model.Select().Unscoped().MustCount(); count != 2 {
+				t.Errorf("Unscoped().Count() after HardDelete = %d, want 2", count)
+			}
+		})
+	}
+}
+
+func TestQueryReturningModel(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testTask struct {
+				__TABLE_NAME__ string `tasks`
+
+				Id     int
+				Title  string
+				Status string
+				Labels []string `jsonb:"meta"`
+			}
+
+			model := psql.NewModel(testTask{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			var inserted testTask
+			model.Insert("Title", "a", "Status", "new", "Labels", []string{"x"}).ReturningModel().MustQuery(&inserted)
+			want := testTask{__TABLE_NAME__: "tasks", Id: 1, Title: "a", Status: "new", Labels: []string{"x"}}
+			if !reflect.DeepEqual(inserted, want) {
+				t.Errorf("inserted = %+v, want %+v", inserted, want)
+			}
+
+			model.Insert([]testTask{{Title: "b", Status: "new"}, {Title: "c", Status: "done"}}).MustExecute()
+
+			var updated []testTask
+			model.Update("Status", "started").Where("status = $1", "new").ReturningModel().MustQuery(&updated)
+			if len(updated) != 2 || updated[0].Status != "started" || updated[1].Status != "started" {
+				t.Errorf("updated = %+v", updated)
+			}
+
+			var deleted []testTask
+			model.Delete().Where("title = $1", "a").ReturningModel().MustQuery(&deleted)
+			want = testTask{__TABLE_NAME__: "tasks", Id: 1, Title: "a", Status: "started", Labels: []string{"x"}}
+			if len(deleted) != 1 || !reflect.DeepEqual(deleted[0], want) {
+				t.Errorf("deleted = %+v, want [%+v]", deleted, want)
+			}
+			if count := model.MustCount(); count != 2 {
+				t.Errorf("Count() = %d, want 2", count)
+			}
+		})
+	}
+}
+
+func TestQueryWriteGuards(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testItem struct {
+				__TABLE_NAME__ string `items`
+
+				Id     int
+				Status string
+			}
+
+			model := psql.NewModel(testItem{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+
+			model.Insert([]testItem{{Status: "a"}, {Status: "a"}, {Status: "b"}}).MustExecute()
+
+			if err := model.Update("Status", "c").Execute(); err != psql.ErrNoConditions {
+				t.Errorf("Update without conditions: err = %v, want ErrNoConditions", err)
+			}
+			if err := model.Delete().Execute(); err != psql.ErrNoConditions {
+				t.Errorf("Delete without conditions: err = %v, want ErrNoConditions", err)
+			}
+
+			ctx := context.Background()
+			err := model.Update("Status", "c").Where("status = $1", "a").ExecuteExpect(ctx, nil, 1)
+			if !errors.Is(err, psql.ErrUnexpectedRowsAffected) {
+				t.Errorf("ExecuteExpect: err = %v, want ErrUnexpectedRowsAffected", err)
+			}
+			if count := model.Where("status = $1", "c").MustCount(); count != 0 {
+				t.Errorf("count = %d after rollback, want 0", count)
+			}
+
+			var rowsAffected int
+			model.Update("Status", "c").Where("status = $1", "a").MustExecuteExpect(ctx, nil, 2, &rowsAffected)
+			if rowsAffected != 2 {
+				t.Errorf("rowsAffected = %d, want 2", rowsAffected)
+			}
+
+			model.MustTransactionCtx(ctx, func(ctx context.Context, tx psql.Tx) error {
+				err := model.Delete().Where("status IN ('b', 'c')").MaxAffected(2).ExecuteCtxTx(ctx, tx)
+				if !errors.Is(err, psql.ErrUnexpectedRowsAffected) {
+					t.Errorf("MaxAffected: err = %v, want ErrUnexpectedRowsAffected", err)
+				}
+				// the transaction can continue after the savepoint is rolled back
+				model.Delete().Where("status = $1", "b").MaxAffected(2).MustExecuteCtxTx(ctx, tx)
+				return nil
+			})
+			if count := model.MustCount(); count != 2 {
+				t.Errorf("count = %d, want 2", count)
+			}
+
+			var items []testItem
+			err = model.Clone().SetMaxQueryRows(1).Find().Query(&items)
+			if !errors.Is(err, psql.ErrTooManyRows) {
+				t.Errorf("SetMaxQueryRows: err = %v, want ErrTooManyRows", err)
+			}
+		})
+	}
+}
+
+func TestQueryDryRun(t *testing.T) {
+	connections := getQueryConnections(t)
+
+	for _, conn := range connections {
+		connName := fmt.Sprintf("%T", conn)
+		t.Run(connName, func(t *testing.T) {
+			defer conn.Close()
+
+			type testNote struct {
+				__TABLE_NAME__ string `notes`
+
+				Id   int
+				Body string
+			}
+
+			model := psql.NewModel(testNote{}, conn)
+
+			// Ensure cleanup happens even on test failure
+			t.Cleanup(func() {
+				model.NewSQL(model.DropSchema()).Execute()
+			})
+
+			model.NewSQL(model.DropSchema()).MustExecute()
+			model.NewSQL(model.Schema()).MustExecute()
+			model.Insert("Body", "a").MustExecute()
+
+			var plans []string
+			dry := model.DryRun(func(plan string) { plans = append(plans, plan) })
+			dry.Update("Body", "b").Where("id = $1", 1).MustExecute()
+			dry.Delete().Where("id = $1", 1).MustExecute()
+			dry.Insert("Body", "c").MustExecute()
+
+			if n := len(dry.Statements()); n != 3 {
+				t.Errorf("len(Statements()) = %d, want 3", n)
+			}
+			if len(plans) != 3 || !strings.Contains(plans[0], "Update on notes") {
+				t.Errorf("plans = %q", plans)
+			}
+			var notes []testNote
+			model.Find().MustQuery(&notes)
+			if len(notes) != 1 || notes[0].Body != "a" {
+				t.Errorf("notes = %+v, want unchanged", notes)
+			}
+		})
+	}
+}
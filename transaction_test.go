@@ -0,0 +1,336 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gopsql/logger"
+)
+
+// Test struct for transaction tests
+type transactionTestStruct struct {
+	Id   int
+	Name string
+}
+
+func statementsSQL(m *Model) []string {
+	sqls := []string{}
+	for _, stmt := range m.Statements() {
+		sqls = append(sqls, stmt.SQL)
+	}
+	return sqls
+}
+
+func TestTransactionSavepoints(t *testing.T) {
+	t.Parallel()
+	m := NewModel(transactionTestStruct{}).DryRun()
+
+	errInner := errors.New("inner")
+	err := m.TransactionCtx(context.Background(), func(ctx context.Context, tx Tx) error {
+		m.Insert("Name", "a").MustExecuteCtxTx(ctx, tx)
+		if err := m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+			m.Insert("Name", "b").MustExecuteCtxTx(ctx, tx)
+			return m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+		if err := m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+			return errInner
+		}); err != errInner {
+			t.Errorf("inner error = %v, want %v", err, errInner)
+		}
+		if err := m.TransactionCtxTx(ctx, tx, func(ctx context.Context, tx Tx) error {
+			panic("oops")
+		}); err == nil || err.Error() != "oops" {
+			t.Errorf("inner panic error = %v, want oops", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"INSERT INTO transaction_test_structs (name) VALUES ($1)",
+		"SAVEPOINT sp_1",
+		"INSERT INTO transaction_test_structs (name) VALUES ($1)",
+		"SAVEPOINT sp_2",
+		"RELEASE SAVEPOINT sp_2",
+		"RELEASE SAVEPOINT sp_1",
+		"SAVEPOINT sp_1",
+		"ROLLBACK TO SAVEPOINT sp_1",
+		"SAVEPOINT sp_1",
+		"ROLLBACK TO SAVEPOINT sp_1",
+	}
+	if got := statementsSQL(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+}
+
+func TestTransactionContext(t *testing.T) {
+	t.Parallel()
+	m := NewModel(transactionTestStruct{}).DryRun()
+
+	if tx := txFromContext(context.Background()); tx != nil {
+		t.Errorf("txFromContext = %v, want nil", tx)
+	}
+	err := m.TransactionCtx(context.Background(), func(ctx context.Context, tx Tx) error {
+		if got := txFromContext(ctx); got != tx {
+			t.Errorf("txFromContext = %v, want %v", got, tx)
+		}
+		if got := txFromContext(WithoutTx(ctx)); got != nil {
+			t.Errorf("txFromContext(WithoutTx) = %v, want nil", got)
+		}
+		m.Insert("Name", "a").MustExecuteCtx(ctx)
+		m.MustCountCtx(ctx)
+		if err := m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+			return nil
+		}); err != nil {
+			return err
+		}
+		return m.TransactionCtx(WithoutTx(ctx), func(ctx context.Context, tx Tx) error {
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"INSERT INTO transaction_test_structs (name) VALUES ($1)",
+		"SELECT COUNT(*) FROM transaction_test_structs",
+		"SAVEPOINT sp_1",
+		"RELEASE SAVEPOINT sp_1",
+	}
+	if got := statementsSQL(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+}
+
+func TestTransactionOptions(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		opts TxOptions
+		want string
+	}{
+		{TxOptions{}, ""},
+		{TxOptions{Isolation: Serializable}, "ISOLATION LEVEL SERIALIZABLE"},
+		{TxOptions{ReadOnly: true}, "READ ONLY"},
+		{TxOptions{Isolation: RepeatableRead, ReadOnly: true}, "ISOLATION LEVEL REPEATABLE READ READ ONLY"},
+		{TxOptions{Isolation: Serializable, ReadOnly: true, Deferrable: true}, "ISOLATION LEVEL SERIALIZABLE READ ONLY DEFERRABLE"},
+	} {
+		if got := test.opts.String(); got != test.want {
+			t.Errorf("%#v.String() = %q, want %q", test.opts, got, test.want)
+		}
+	}
+
+	m := NewModel(transactionTestStruct{}).DryRun()
+	opts := TxOptions{Isolation: Serializable, ReadOnly: true, Deferrable: true}
+	err := m.TransactionOptsCtx(context.Background(), opts, func(ctx context.Context, tx Tx) error {
+		m.MustCountCtx(ctx)
+		return m.TransactionOptsCtx(ctx, opts, func(ctx context.Context, tx Tx) error {
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"SET TRANSACTION ISOLATION LEVEL SERIALIZABLE READ ONLY DEFERRABLE",
+		"SELECT COUNT(*) FROM transaction_test_structs",
+		"SAVEPOINT sp_1",
+		"RELEASE SAVEPOINT sp_1",
+	}
+	if got := statementsSQL(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+
+	called := false
+	err = m.TransactionOptsCtx(context.Background(), TxOptions{Isolation: "snapshot"}, func(ctx context.Context, tx Tx) error {
+		called = true
+		return nil
+	})
+	if err != ErrInvalidIsolation || called {
+		t.Errorf("error = %v, called = %t, want ErrInvalidIsolation without calling the block", err, called)
+	}
+}
+
+type (
+	retryTestDB struct {
+		*dryRunDB
+	}
+
+	retryTestError string
+)
+
+func (retryTestDB) ErrGetCode(err error) string {
+	if e, ok := err.(retryTestError); ok {
+		return string(e)
+	}
+	return "unknown"
+}
+
+func (e retryTestError) Error() string {
+	return "error " + string(e)
+}
+
+func TestTransactionRetry(t *testing.T) {
+	t.Parallel()
+	m := NewModel(transactionTestStruct{}, retryTestDB{&dryRunDB{}})
+	policy := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+
+	var attempts int
+	err := m.TransactionRetryCtx(context.Background(), policy, func(ctx context.Context, tx Tx) error {
+		attempts++
+		if attempts == 1 {
+			return retryTestError(errCodeSerializationFailure)
+		}
+		if attempts == 2 {
+			panic(fmt.Errorf("wrapped: %w", retryTestError(errCodeDeadlockDetected)))
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("err = %v, attempts = %d, want nil and 3", err, attempts)
+	}
+
+	attempts = 0
+	err = m.TransactionRetryCtx(context.Background(), policy, func(ctx context.Context, tx Tx) error {
+		attempts++
+		return retryTestError(errCodeSerializationFailure)
+	})
+	if !errors.Is(err, retryTestError(errCodeSerializationFailure)) || attempts != 3 {
+		t.Errorf("err = %v, attempts = %d, want serialization failure and 3", err, attempts)
+	}
+	if want := "transaction failed after 3 attempts: error 40001"; err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %s", err, want)
+	}
+
+	attempts = 0
+	errOther := retryTestError("23505")
+	err = m.TransactionRetryCtx(context.Background(), policy, func(ctx context.Context, tx Tx) error {
+		attempts++
+		return errOther
+	})
+	if err != errOther || attempts != 1 {
+		t.Errorf("err = %v, attempts = %d, want %v and 1", err, attempts, errOther)
+	}
+
+	attempts = 0
+	err = m.TransactionCtx(context.Background(), func(ctx context.Context, tx Tx) error {
+		return m.TransactionRetryCtx(ctx, policy, func(ctx context.Context, tx Tx) error {
+			attempts++
+			return retryTestError(errCodeSerializationFailure)
+		})
+	})
+	if err != retryTestError(errCodeSerializationFailure) || attempts != 1 {
+		t.Errorf("nested err = %v, attempts = %d, want serialization failure and 1", err, attempts)
+	}
+}
+
+type hooksTestLogger struct {
+	logger.Logger
+	errors []string
+}
+
+func (l *hooksTestLogger) Debug(args ...interface{}) {}
+
+func (l *hooksTestLogger) Error(args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprint(args...))
+}
+
+func TestTransactionHooks(t *testing.T) {
+	t.Parallel()
+	log := &hooksTestLogger{}
+	m := NewModel(transactionTestStruct{}, log).DryRun()
+
+	var calls []string
+	hook := func(name string) func() {
+		return func() { calls = append(calls, name) }
+	}
+	err := m.TransactionCtx(context.Background(), func(ctx context.Context, tx Tx) error {
+		AfterCommit(ctx, hook("commit 1"))
+		AfterRollback(ctx, hook("rollback 1"))
+		AfterCommit(ctx, func() { panic("oops") })
+		m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+			AfterCommit(ctx, hook("commit 2"))
+			AfterRollback(ctx, hook("rollback 2"))
+			return nil
+		})
+		m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+			AfterCommit(ctx, hook("commit 3"))
+			AfterRollback(ctx, hook("rollback 3"))
+			return errors.New("rollback")
+		})
+		if want := []string{"rollback 3"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("calls in transaction = %q, want %q", calls, want)
+		}
+		AfterCommit(WithoutTx(ctx), hook("no transaction"))
+		AfterRollback(WithoutTx(ctx), hook("no transaction rollback"))
+		return nil
+	})
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || hookErr.Err != nil || err.Error() != "AfterCommit hook panicked: oops" {
+		t.Fatalf("err = %v, want HookError of a committed transaction", err)
+	}
+	want := []string{"rollback 3", "no transaction", "commit 1", "commit 2"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+	if want := []string{"AfterCommit hook panicked: oops"}; !reflect.DeepEqual(log.errors, want) {
+		t.Errorf("errors = %q, want %q", log.errors, want)
+	}
+
+	calls = nil
+	m.TransactionCtx(context.Background(), func(ctx context.Context, tx Tx) error {
+		AfterCommit(ctx, hook("commit 1"))
+		AfterRollback(ctx, hook("rollback 1"))
+		m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+			AfterCommit(ctx, hook("commit 2"))
+			AfterRollback(ctx, hook("rollback 2"))
+			return nil
+		})
+		panic("rollback")
+	})
+	if want := []string{"rollback 1", "rollback 2"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+
+	// panics are reported without a logger
+	errRollback := errors.New("rollback")
+	err = m.Quiet().TransactionCtx(context.Background(), func(ctx context.Context, tx Tx) error {
+		AfterRollback(ctx, func() { panic("oops") })
+		AfterRollback(ctx, func() { panic(errors.New("again")) })
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) || err.Error() != "rollback; AfterRollback hook panicked: oops; AfterRollback hook panicked: again" {
+		t.Errorf("err = %v, want rollback and panics of hooks", err)
+	}
+	if len(log.errors) != 1 {
+		t.Errorf("errors = %q, want no more errors of a quiet model", log.errors)
+	}
+
+	calls = nil
+	tx, err := m.connection.BeginTx(context.Background(), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.TransactionCtxTx(context.Background(), tx, func(ctx context.Context, tx Tx) error {
+		if err := AfterCommit(ctx, hook("commit 1")); err != ErrUnknownTransaction {
+			t.Errorf("AfterCommit() error = %v, want ErrUnknownTransaction", err)
+		}
+		return m.TransactionCtx(ctx, func(ctx context.Context, tx Tx) error {
+			if err := AfterRollback(ctx, hook("rollback 1")); err != ErrUnknownTransaction {
+				t.Errorf("AfterRollback() error = %v, want ErrUnknownTransaction", err)
+			}
+			return errors.New("rollback")
+		})
+	})
+	if calls != nil {
+		t.Errorf("calls of unknown transaction = %q, want none", calls)
+	}
+}
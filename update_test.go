@@ -1,8 +1,13 @@
 package psql
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/gopsql/db"
 )
 
 // Test struct for UPDATE tests
@@ -110,6 +115,13 @@ func TestUpdateReturning(t *testing.T) {
 			},
 			wantSQL: "UPDATE update_test_structs SET name = $1 RETURNING id, name",
 		},
+		{
+			name: "model",
+			build: func() *UpdateSQL {
+				return m.Update("Name", "test").ReturningModel()
+			},
+			wantSQL: "UPDATE update_test_structs SET name = $1 RETURNING update_test_structs.id, update_test_structs.name, update_test_structs.email",
+		},
 	}
 
 	for _, tt := range tests {
@@ -342,6 +354,58 @@ func TestUpdateWithJsonb(t *testing.T) {
 	}
 }
 
+func TestUpdateFrom(t *testing.T) {
+	t.Parallel()
+	m := NewModel(updateTestStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *UpdateSQL
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "from table",
+			build: func() *UpdateSQL {
+				return m.Update("Name", String("users.name")).From("users").
+					Where("update_test_structs.id = users.id").Where("users.status = $1", "active")
+			},
+			wantSQL:  "UPDATE update_test_structs SET name = users.name FROM users WHERE (update_test_structs.id = users.id) AND (users.status = $1)",
+			wantArgs: []interface{}{"active"},
+		},
+		{
+			name: "multiple from",
+			build: func() *UpdateSQL {
+				return m.Update("Name", String("b.name")).From("a").From("b", "c")
+			},
+			wantSQL:  "UPDATE update_test_structs SET name = b.name FROM a, b, c",
+			wantArgs: []interface{}{},
+		},
+		{
+			name: "from subquery",
+			build: func() *UpdateSQL {
+				sub := m.Select("id", "name").Where("status = $1", "new").Where("id > $2", 10)
+				return m.Update("Name", "x").Where("update_test_structs.status = $1", "old").
+					FROM("t", sub).Where("update_test_structs.id = t.id AND t.name <> $4", "y")
+			},
+			wantSQL:  "UPDATE update_test_structs SET name = $5 FROM (SELECT id, name FROM update_test_structs WHERE (status = $2) AND (id > $3)) AS t WHERE (update_test_structs.status = $1) AND (update_test_structs.id = t.id AND t.name <> $4)",
+			wantArgs: []interface{}{"old", "new", 10, "y", "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.build().StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
 func TestUpdateTap(t *testing.T) {
 	t.Parallel()
 	m := NewModel(updateTestStruct{})
@@ -411,3 +475,636 @@ func TestUpdateMixedJsonbAndRegularFields(t *testing.T) {
 		t.Errorf("String() = %q, want %q", got, want)
 	}
 }
+
+func TestUpdateStruct(t *testing.T) {
+	t.Parallel()
+	m := NewModel(updateTestStruct{})
+	mj := NewModel(updateJsonbStruct{})
+
+	original := updateTestStruct{Id: 1, Name: "a", Email: "a@example.com"}
+	modified := original
+	modified.Name = "b"
+
+	update, err := m.updateStruct(original, &modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotSQL, gotArgs := update.StringValues()
+	if want := "UPDATE update_test_structs SET name = $2 WHERE id = $1"; gotSQL != want {
+		t.Errorf("SQL = %q, want %q", gotSQL, want)
+	}
+	if want := []interface{}{1, "b"}; !reflect.DeepEqual(gotArgs, want) {
+		t.Errorf("Args = %v, want %v", gotArgs, want)
+	}
+
+	jsonbOriginal := updateJsonbStruct{Id: 2, Picture: "a.jpg", Tags: "a"}
+	jsonbModified := jsonbOriginal
+	jsonbModified.Tags = "b"
+	update, err = mj.updateStruct(&jsonbOriginal, jsonbModified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotSQL, gotArgs = update.StringValues()
+	if want := "UPDATE update_jsonb_structs SET meta = jsonb_set(COALESCE(meta, '{}'::jsonb), '{tags}', $2) WHERE id = $1"; gotSQL != want {
+		t.Errorf("SQL = %q, want %q", gotSQL, want)
+	}
+	if want := []interface{}{2, `"b"`}; !reflect.DeepEqual(gotArgs, want) {
+		t.Errorf("Args = %v, want %v", gotArgs, want)
+	}
+
+	if update, err := m.updateStruct(original, original); update != nil || err != nil {
+		t.Errorf("updateStruct() without changes = %v, %v, want nil, nil", update, err)
+	}
+	if _, err := m.updateStruct(jsonbOriginal, jsonbModified); err != ErrInvalidTarget {
+		t.Errorf("updateStruct() of other struct error = %v, want ErrInvalidTarget", err)
+	}
+
+	type noKeyStruct struct {
+		Name string
+	}
+	mn := NewModel(noKeyStruct{})
+	if _, err := mn.updateStruct(noKeyStruct{"a"}, noKeyStruct{"b"}); err != ErrNoPrimaryKey {
+		t.Errorf("updateStruct() without primary key error = %v, want ErrNoPrimaryKey", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	type diffStruct struct {
+		Id        int
+		Name      string
+		Secret    string `dataType:"-"`
+		CreatedAt time.Time
+		DeletedAt *time.Time
+	}
+	m := NewModel(diffStruct{})
+
+	now := time.Now()
+	original := diffStruct{Id: 1, Name: "a", CreatedAt: now, DeletedAt: &now}
+	utc, deletedAt := now.UTC(), now.Round(0)
+	modified := diffStruct{Id: 1, Name: "b", Secret: "x", CreatedAt: utc, DeletedAt: &deletedAt}
+	changes, err := m.Diff(original, &modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	for field, value := range changes {
+		got[field.Name] = value
+	}
+	if want := map[string]interface{}{"Name": "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+
+	modified.DeletedAt = nil
+	if changes, err := m.Diff(original, modified); err != nil || len(changes) != 2 {
+		t.Errorf("Diff() = %v, %v, want Name and DeletedAt", changes, err)
+	}
+
+	var nilStruct *diffStruct
+	for name, modified := range map[string]interface{}{
+		"other struct": updateTestStruct{},
+		"nil pointer":  nilStruct,
+		"nil":          nil,
+	} {
+		if _, err := m.Diff(original, modified); err != ErrInvalidTarget {
+			t.Errorf("Diff(%s) error = %v, want ErrInvalidTarget", name, err)
+		}
+		if err := m.UpdateStruct(context.Background(), nil, original, modified); err != ErrInvalidTarget {
+			t.Errorf("UpdateStruct(%s) error = %v, want ErrInvalidTarget", name, err)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	type trackedStruct struct {
+		Id   int
+		Name string
+		Tags []string
+		Meta map[string]int
+	}
+	m := NewModel(trackedStruct{})
+
+	s := trackedStruct{Id: 1, Name: "a", Tags: []string{"x"}, Meta: map[string]int{"a": 1}}
+	tracker := m.Track(&s)
+	if changes := tracker.Changes(); len(changes) != 0 {
+		t.Errorf("Changes() = %v, want none", changes)
+	}
+
+	s.Tags[0] = "y"
+	s.Meta["a"] = 2
+	got := map[string]interface{}{}
+	for field, value := range tracker.Changes() {
+		got[field.Name] = value
+	}
+	want := map[string]interface{}{
+		"Tags": []string{"y"},
+		"Meta": map[string]int{"a": 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Changes() = %v, want %v", got, want)
+	}
+
+	if err := m.Track(s).Update(context.Background(), nil); err != ErrMustBePointer {
+		t.Errorf("Update() error = %v, want ErrMustBePointer", err)
+	}
+}
+
+func TestUpdateVersion(t *testing.T) {
+	t.Parallel()
+
+	type versionStruct struct {
+		Id          int
+		Name        string
+		LockVersion int `column:",version"`
+	}
+	m := NewModel(versionStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *UpdateSQL
+		wantSQL  string
+		wantArgs []interface{}
+		checks   bool
+	}{
+		{
+			name: "increment version",
+			build: func() *UpdateSQL {
+				return m.Update("Name", "a").Where("id = $1", 1)
+			},
+			wantSQL:  "UPDATE version_structs SET name = $2, lock_version = lock_version + 1 WHERE id = $1",
+			wantArgs: []interface{}{1, "a"},
+		},
+		{
+			name: "check version",
+			build: func() *UpdateSQL {
+				return m.Update("Name", "a", "LockVersion", 3).Where("id = $1", 1).Returning("lock_version")
+			},
+			wantSQL:  "UPDATE version_structs SET name = $2, lock_version = lock_version + 1 WHERE (id = $1) AND (lock_version = $3) RETURNING lock_version",
+			wantArgs: []interface{}{1, "a", 3},
+			checks:   true,
+		},
+		{
+			name: "check version only",
+			build: func() *UpdateSQL {
+				return m.Update("LockVersion", 3)
+			},
+			wantSQL:  "UPDATE version_structs SET lock_version = lock_version + 1 WHERE lock_version = $1",
+			wantArgs: []interface{}{3},
+			checks:   true,
+		},
+		{
+			name: "set version explicitly",
+			build: func() *UpdateSQL {
+				return m.Update("LockVersion", String("0")).Where("id = $1", 1)
+			},
+			wantSQL:  "UPDATE version_structs SET lock_version = 0 WHERE id = $1",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name: "raw sql",
+			build: func() *UpdateSQL {
+				return m.NewSQL("UPDATE version_structs SET name = $?", "a").AsUpdate("LockVersion", 3)
+			},
+			wantSQL:  "UPDATE version_structs SET name = $1",
+			wantArgs: []interface{}{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := tt.build()
+			gotSQL, gotArgs := sql.StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+			if got := sql.checksVersion(); got != tt.checks {
+				t.Errorf("checksVersion() = %v, want %v", got, tt.checks)
+			}
+		})
+	}
+
+	original := versionStruct{Id: 1, Name: "a", LockVersion: 2}
+	modified := original
+	modified.Name = "b"
+	update, err := m.updateStruct(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "UPDATE version_structs SET name = $2, lock_version = lock_version + 1 WHERE (id = $1) AND (lock_version = $3)"
+	if got := update.String(); got != want {
+		t.Errorf("updateStruct() = %q, want %q", got, want)
+	}
+
+	if got := m.ColumnDataTypes()["lock_version"]; got != "bigint DEFAULT 0 NOT NULL" {
+		t.Errorf("data type = %q, want %q", got, "bigint DEFAULT 0 NOT NULL")
+	}
+	type pointerVersionStruct struct {
+		Version *int32 `column:",version"`
+	}
+	if got := NewModel(pointerVersionStruct{}).ColumnDataTypes()["version"]; got != "integer DEFAULT 0 NOT NULL" {
+		t.Errorf("data type = %q, want %q", got, "integer DEFAULT 0 NOT NULL")
+	}
+}
+
+func TestUpdateNestedJsonb(t *testing.T) {
+	t.Parallel()
+
+	type settings struct {
+		Theme string `json:"theme"`
+		Font  struct {
+			Size int
+		} `json:"font"`
+	}
+	type nestedStruct struct {
+		Id       int
+		Name     string
+		Picture  string    `jsonb:"meta"`
+		Settings *settings `jsonb:"meta"`
+	}
+	m := NewModel(nestedStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *UpdateSQL
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "nested key",
+			build: func() *UpdateSQL {
+				return m.Update(m.FieldChanges(RawChanges{"Settings.Theme": "dark"})).Where("id = $1", 1)
+			},
+			wantSQL:  "UPDATE nested_structs SET meta = jsonb_set(jsonb_set(COALESCE(meta, '{}'::jsonb), '{settings}', COALESCE(NULLIF(meta #> '{settings}', 'null'::jsonb), '{}'::jsonb)), '{settings,theme}', $2, true) WHERE id = $1",
+			wantArgs: []interface{}{1, `"dark"`},
+		},
+		{
+			name: "deeply nested keys",
+			build: func() *UpdateSQL {
+				return m.Update(m.FieldChanges(RawChanges{"Settings.Font.Size": 12, "Settings.Theme": "dark", "Picture": "a.jpg"}))
+			},
+			wantSQL: "UPDATE nested_structs SET meta = jsonb_set(jsonb_set(jsonb_set(jsonb_set(jsonb_set(COALESCE(meta, '{}'::jsonb), " +
+				"'{settings}', COALESCE(NULLIF(meta #> '{settings}', 'null'::jsonb), '{}'::jsonb)), " +
+				"'{settings,font}', COALESCE(NULLIF(meta #> '{settings,font}', 'null'::jsonb), '{}'::jsonb)), " +
+				"'{picture}', $1), '{settings,theme}', $2, true), '{settings,font,Size}', $3, true)",
+			wantArgs: []interface{}{`"a.jpg"`, `"dark"`, "12"},
+		},
+		{
+			name: "remove key",
+			build: func() *UpdateSQL {
+				return m.Update("Picture", Remove, "Name", Remove)
+			},
+			wantSQL:  "UPDATE nested_structs SET name = NULL, meta = COALESCE(meta, '{}'::jsonb) #- '{picture}'",
+			wantArgs: []interface{}{},
+		},
+		{
+			name: "remove nested key",
+			build: func() *UpdateSQL {
+				return m.Update(m.FieldChanges(RawChanges{"Settings.Theme": Remove}))
+			},
+			wantSQL:  "UPDATE nested_structs SET meta = jsonb_set(COALESCE(meta, '{}'::jsonb), '{settings}', COALESCE(NULLIF(meta #> '{settings}', 'null'::jsonb), '{}'::jsonb)) #- '{settings,theme}'",
+			wantArgs: []interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.build().StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+type (
+	// affectedTestDB is a dry run connection of which every statement
+	// affects (and returns) the given number of rows.
+	affectedTestDB struct {
+		*dryRunDB
+		affected int64
+	}
+
+	affectedTestRows struct {
+		dryRunRows
+		left int64
+	}
+
+	affectedTestTx struct {
+		db *affectedTestDB
+	}
+
+	affectedTestResult int64
+)
+
+func (d *affectedTestDB) ExecContext(ctx context.Context, query string, args ...interface{}) (db.Result, error) {
+	return affectedTestResult(d.affected), d.record(ctx, query, args)
+}
+
+func (d *affectedTestDB) QueryContext(ctx context.Context, query string, args ...interface{}) (db.Rows, error) {
+	return &affectedTestRows{left: d.affected}, d.record(ctx, query, args)
+}
+
+func (r *affectedTestRows) Next() bool {
+	r.left--
+	return r.left >= 0
+}
+
+func (d *affectedTestDB) BeginTx(ctx context.Context, isolationLevel string, readOnly bool) (db.Tx, error) {
+	return affectedTestTx{d}, nil
+}
+
+func (t affectedTestTx) ExecContext(ctx context.Context, query string, args ...interface{}) (db.Result, error) {
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t affectedTestTx) QueryContext(ctx context.Context, query string, args ...interface{}) (db.Rows, error) {
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t affectedTestTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) db.Row {
+	return t.db.QueryRowContext(ctx, query, args...)
+}
+
+func (t affectedTestTx) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (t affectedTestTx) Rollback(ctx context.Context) error {
+	t.db.record(ctx, "ROLLBACK", nil)
+	return nil
+}
+
+func (r affectedTestResult) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+func TestUpdateManyVersion(t *testing.T) {
+	t.Parallel()
+
+	type versionStruct struct {
+		Id          int
+		Name        string
+		LockVersion int `column:",version"`
+	}
+	conn := &affectedTestDB{dryRunDB: &dryRunDB{}}
+	m := NewModel(versionStruct{}, conn)
+
+	rows := []Changes{
+		m.FieldChanges(RawChanges{"Id": 1, "Name": "a", "LockVersion": 3}),
+		m.FieldChanges(RawChanges{"Id": 2, "Name": "b", "LockVersion": 5}),
+	}
+	got, args := m.UpdateMany("Id", rows).StringValues()
+	want := "UPDATE version_structs SET name = v.name, lock_version = version_structs.lock_version + 1 " +
+		"FROM (VALUES ($1::integer, $2::text, $3::bigint), ($4::integer, $5::text, $6::bigint)) AS v(id, name, lock_version) " +
+		"WHERE (version_structs.id = v.id) AND (version_structs.lock_version = v.lock_version)"
+	if got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, "a", 3, 2, "b", 5}) {
+		t.Errorf("Args = %v", args)
+	}
+
+	got = m.UpdateMany("Id", []Changes{m.FieldChanges(RawChanges{"Id": 1, "Name": "a"})}).String()
+	want = "UPDATE version_structs SET name = v.name, lock_version = version_structs.lock_version + 1 " +
+		"FROM (VALUES ($1::integer, $2::text)) AS v(id, name) WHERE version_structs.id = v.id"
+	if got != want {
+		t.Errorf("SQL without version = %q, want %q", got, want)
+	}
+
+	conn.affected = 1
+	if err := m.UpdateMany("Id", rows).Execute(); err != ErrStaleObject {
+		t.Errorf("Execute() error = %v, want ErrStaleObject", err)
+	}
+	if stmts := conn.statements; len(stmts) != 2 || stmts[1].SQL != "ROLLBACK" {
+		t.Errorf("statements = %v, want UPDATE and ROLLBACK", stmts)
+	}
+	conn.affected = 2
+	var n int
+	if err := m.UpdateMany("Id", rows).Execute(&n); err != nil || n != 2 {
+		t.Errorf("Execute() = %d, %v, want 2 rows", n, err)
+	}
+}
+
+func TestUpdateManyMaxQueryRows(t *testing.T) {
+	t.Parallel()
+	conn := &affectedTestDB{dryRunDB: &dryRunDB{}, affected: 1}
+	m := NewModel(updateTestStruct{}, conn)
+
+	// two statements of one row each
+	update := func(m *Model) *UpdateSQL {
+		return m.UpdateMany("Id", []Changes{
+			m.Changes(RawChanges{"Id": 1, "Name": "a"}),
+			m.Changes(RawChanges{"Id": 2, "Email": "b@example.com"}),
+		}).Returning("id")
+	}
+	var ids []int
+	if err := update(m).Query(&ids); err != nil || len(ids) != 2 {
+		t.Errorf("Query() = %v, %v, want 2 ids", ids, err)
+	}
+	ids = nil
+	if err := update(m.Clone().SetMaxQueryRows(1)).Query(&ids); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("Query() error = %v, want ErrTooManyRows", err)
+	}
+
+	var id int
+	if err := m.Update("Name", "a").Where("id = $1", 1).MaxAffected(1).QueryRow(&id); err != ErrUnsupportedMaxAffected {
+		t.Errorf("QueryRow() error = %v, want ErrUnsupportedMaxAffected", err)
+	}
+	if err := m.Update("Name", "a").Where("id = $1", 1).MaxAffected(1).Query(&ids); err != ErrUnsupportedMaxAffected {
+		t.Errorf("Query() error = %v, want ErrUnsupportedMaxAffected", err)
+	}
+}
+
+func TestUpdateMany(t *testing.T) {
+	t.Parallel()
+	type settings struct {
+		Theme string `json:"theme"`
+	}
+	type nestedStruct struct {
+		Id       int
+		Settings settings `jsonb:"meta"`
+	}
+	m := NewModel(updateTestStruct{})
+	mj := NewModel(updateJsonbStruct{})
+	mn := NewModel(nestedStruct{})
+
+	tests := []struct {
+		name     string
+		build    func() *UpdateSQL
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "same fields",
+			build: func() *UpdateSQL {
+				return m.UpdateMany("Id", []Changes{
+					m.Changes(RawChanges{"Id": 1, "Name": "a", "Email": "a@example.com"}),
+					m.Changes(RawChanges{"Id": 2, "Name": "b", "Email": "b@example.com"}),
+				})
+			},
+			wantSQL: "UPDATE update_test_structs SET name = v.name, email = v.email " +
+				"FROM (VALUES ($1::integer, $2::text, $3::text), ($4::integer, $5::text, $6::text)) AS v(id, name, email) " +
+				"WHERE update_test_structs.id = v.id",
+			wantArgs: []interface{}{1, "a", "a@example.com", 2, "b", "b@example.com"},
+		},
+		{
+			name: "where and returning",
+			build: func() *UpdateSQL {
+				return m.UpdateMany("Id", []Changes{
+					m.Changes(RawChanges{"Id": 1, "Name": "a"}),
+				}).Where("update_test_structs.email <> $?", "").Returning("update_test_structs.id")
+			},
+			wantSQL: "UPDATE update_test_structs SET name = v.name FROM (VALUES ($2::integer, $3::text)) AS v(id, name) " +
+				"WHERE (update_test_structs.id = v.id) AND (update_test_structs.email <> $1) RETURNING update_test_structs.id",
+			wantArgs: []interface{}{"", 1, "a"},
+		},
+		{
+			name: "expressions and remove",
+			build: func() *UpdateSQL {
+				return m.UpdateMany("Id", []Changes{
+					m.Changes(RawChanges{"Id": 1, "Name": StringWithArg("upper($?)", "a")}),
+					m.Changes(RawChanges{"Id": 2, "Name": Remove}),
+					m.Changes(RawChanges{"Id": 3, "Name": String("'c'")}),
+				})
+			},
+			wantSQL: "UPDATE update_test_structs SET name = v.name " +
+				"FROM (VALUES ($1::integer, upper($2)), ($3::integer, NULL::text), ($4::integer, 'c')) AS v(id, name) " +
+				"WHERE update_test_structs.id = v.id",
+			wantArgs: []interface{}{1, "a", 2, 3},
+		},
+		{
+			name: "different fields",
+			build: func() *UpdateSQL {
+				return m.UpdateMany("Id", []Changes{
+					m.Changes(RawChanges{"Id": 1, "Name": "a"}),
+					m.Changes(RawChanges{"Id": 2, "Email": "b@example.com"}),
+					m.Changes(RawChanges{"Id": 3, "Name": "c"}),
+				})
+			},
+			wantSQL: "UPDATE update_test_structs SET name = v.name FROM (VALUES ($1::integer, $2::text), ($3::integer, $4::text)) " +
+				"AS v(id, name) WHERE update_test_structs.id = v.id; " +
+				"UPDATE update_test_structs SET email = v.email FROM (VALUES ($5::integer, $6::text)) " +
+				"AS v(id, email) WHERE update_test_structs.id = v.id",
+			wantArgs: []interface{}{1, "a", 3, "c", 2, "b@example.com"},
+		},
+		{
+			name: "jsonb",
+			build: func() *UpdateSQL {
+				return mj.UpdateMany("Id", []Changes{
+					mj.Changes(RawChanges{"Id": 1, "Picture": "a.jpg"}),
+					mj.Changes(RawChanges{"Id": 2, "Tags": "b"}),
+				})
+			},
+			wantSQL: "UPDATE update_jsonb_structs SET meta = COALESCE(update_jsonb_structs.meta, '{}'::jsonb) || v.meta " +
+				"FROM (VALUES ($1::integer, $2::jsonb), ($3::integer, $4::jsonb)) AS v(id, meta) " +
+				"WHERE update_jsonb_structs.id = v.id",
+			wantArgs: []interface{}{1, `{"picture":"a.jpg"}`, 2, `{"tags":"b"}`},
+		},
+		{
+			name: "no rows",
+			build: func() *UpdateSQL {
+				return m.UpdateMany("Id", []Changes{})
+			},
+			wantSQL:  "",
+			wantArgs: []interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotArgs := tt.build().StringValues()
+			if gotSQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for name, u := range map[string]*UpdateSQL{
+			"unknown key": m.UpdateMany("Foo", []Changes{m.Changes(RawChanges{"Id": 1, "Name": "a"})}),
+			"jsonb key":   mj.UpdateMany("Picture", []Changes{mj.Changes(RawChanges{"Id": 1, "Picture": "a.jpg"})}),
+			"no key": m.UpdateMany("Id", []Changes{
+				m.Changes(RawChanges{"Id": 1, "Name": "a"}),
+				m.Changes(RawChanges{"Name": "no key"}),
+			}),
+			"jsonb remove": mj.UpdateMany("Id", []Changes{mj.Changes(RawChanges{"Id": 1, "Picture": Remove})}),
+			"nested key":   mn.UpdateMany("Id", []Changes{mn.FieldChanges(RawChanges{"Id": 1, "Settings.Theme": "dark"})}),
+		} {
+			if err := u.guard(); err == nil {
+				t.Errorf("%s: guard() error = nil, want error", name)
+			}
+		}
+		dry := m.DryRun()
+		if err := dry.UpdateMany("Id", []Changes{m.Changes(RawChanges{"Name": "no key"})}).Execute(); err == nil {
+			t.Error("Execute() error = nil, want error of row without key")
+		}
+		if stmts := dry.Statements(); len(stmts) != 0 {
+			t.Errorf("got %d statements, want none", len(stmts))
+		}
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		rows := []Changes{}
+		for i := 1; i <= 3; i++ {
+			rows = append(rows, m.Changes(RawChanges{"Id": i, "Name": "x"}))
+		}
+		u := m.UpdateMany("Id", rows).Where("email = $1", "")
+		stmts := u.manyStatements(5)
+		if len(stmts) != 2 {
+			t.Fatalf("got %d statements, want 2", len(stmts))
+		}
+		want := "UPDATE update_test_structs SET name = v.name FROM (VALUES ($2::integer, $3::text), ($4::integer, $5::text)) " +
+			"AS v(id, name) WHERE (update_test_structs.id = v.id) AND (email = $1)"
+		if stmts[0].sql != want {
+			t.Errorf("SQL = %q, want %q", stmts[0].sql, want)
+		}
+		want = "UPDATE update_test_structs SET name = v.name FROM (VALUES ($2::integer, $3::text)) " +
+			"AS v(id, name) WHERE (update_test_structs.id = v.id) AND (email = $1)"
+		if stmts[1].sql != want {
+			t.Errorf("SQL = %q, want %q", stmts[1].sql, want)
+		}
+		if !reflect.DeepEqual(stmts[1].values, []interface{}{"", 3, "x"}) {
+			t.Errorf("Args = %v", stmts[1].values)
+		}
+	})
+}
+
+func TestUpdateAllowAll(t *testing.T) {
+	t.Parallel()
+	m := NewModel(updateTestStruct{})
+
+	tests := []struct {
+		name  string
+		build func() *UpdateSQL
+		want  error
+	}{
+		{"no conditions", func() *UpdateSQL { return m.Update("Name", "a") }, ErrNoConditions},
+		{"where", func() *UpdateSQL { return m.Update("Name", "a").Where("id = $1", 1) }, nil},
+		{"allow all", func() *UpdateSQL { return m.Update("Name", "a").AllowAll() }, nil},
+		{"from select", func() *UpdateSQL { return m.Where("id = $1", 1).Update("Name", "a") }, nil},
+		{"raw sql", func() *UpdateSQL { return m.NewSQL("UPDATE update_test_structs SET name = 'a'").AsUpdate() }, nil},
+		{"update many", func() *UpdateSQL {
+			return m.UpdateMany("Id", []Changes{m.Changes(RawChanges{"Id": 1, "Name": "a"})})
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.build().guard(); got != tt.want {
+				t.Errorf("guard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
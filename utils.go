@@ -1,11 +1,16 @@
 package psql
 
 import (
+	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 )
 
+var parameterRegexp = regexp.MustCompile(`\$(\d+)`)
+
 var (
 	// DefaultColumnNamer is the default function for transforming struct field
 	// names to database column names. If nil (the default), field names are
@@ -162,3 +167,98 @@ func FieldDataType(fieldName, fieldType string) (dataType string) {
 	}
 	return
 }
+
+// jsonbCastType returns the PostgreSQL type a JSONB value of the given Go type
+// should be cast to. It returns "text" for strings (no cast is needed after
+// ->>) and "jsonb" for slices, maps and structs, which are kept as jsonb.
+func jsonbCastType(fieldType string) string {
+	fieldType = strings.TrimPrefix(fieldType, "*")
+	if strings.HasPrefix(fieldType, "[]") || strings.HasPrefix(fieldType, "map[") {
+		return "jsonb"
+	}
+	switch fieldType {
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32":
+		return "integer"
+	case "int64", "uint64", "int", "uint":
+		return "bigint"
+	case "float32", "float64", "decimal.Decimal":
+		return "numeric"
+	case "bool":
+		return "boolean"
+	case "time.Time":
+		return "timestamptz"
+	case "string":
+		return "text"
+	}
+	return "jsonb"
+}
+
+// shiftParameters adds offset to the number of every positional parameter
+// ($1, $2, ...) in sql, so that it can be appended to a statement that
+// already has offset parameters.
+func shiftParameters(sql string, offset int) string {
+	if offset == 0 {
+		return sql
+	}
+	return parameterRegexp.ReplaceAllStringFunc(sql, func(s string) string {
+		num, err := strconv.Atoi(s[1:])
+		if err != nil { // this should not happen
+			panic(err)
+		}
+		return fmt.Sprintf("$%d", num+offset)
+	})
+}
+
+// textArray encodes a slice or array to a PostgreSQL text array literal, like
+// {"a","b"}. Strings are returned as is, as they are expected to be array
+// literals already.
+func textArray(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("cannot convert %T to text array", value)
+	}
+	elems := make([]string, rv.Len())
+	for i := range elems {
+		elem := fmt.Sprint(rv.Index(i).Interface())
+		elem = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(elem)
+		elems[i] = `"` + elem + `"`
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// castType returns the type name of a column data type definition (like
+// "bigint DEFAULT 0 NOT NULL" or "SERIAL PRIMARY KEY") that values can be
+// cast to, without constraints and defaults. Serial types are converted to
+// their integer types.
+func castType(dataType string) string {
+	var words []string
+	depth := 0
+	for _, word := range strings.Fields(dataType) {
+		if depth == 0 {
+			switch strings.ToUpper(word) {
+			case "DEFAULT", "NOT", "NULL", "PRIMARY", "UNIQUE", "REFERENCES",
+				"CHECK", "GENERATED", "COLLATE", "CONSTRAINT":
+				return castTypeOf(words)
+			}
+		}
+		depth += strings.Count(word, "(") - strings.Count(word, ")")
+		words = append(words, word)
+	}
+	return castTypeOf(words)
+}
+
+func castTypeOf(words []string) string {
+	dataType := strings.Join(words, " ")
+	switch strings.ToUpper(dataType) {
+	case "SMALLSERIAL", "SERIAL2":
+		return "smallint"
+	case "SERIAL", "SERIAL4":
+		return "integer"
+	case "BIGSERIAL", "SERIAL8":
+		return "bigint"
+	}
+	return dataType
+}
@@ -296,3 +296,55 @@ func TestFieldDataType(t *testing.T) {
 		})
 	}
 }
+
+func TestCastType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dataType string
+		want     string
+	}{
+		{"SERIAL PRIMARY KEY", "integer"},
+		{"bigserial", "bigint"},
+		{"bigint DEFAULT 0 NOT NULL", "bigint"},
+		{"numeric(10, 2) DEFAULT 0.0 NOT NULL", "numeric(10, 2)"},
+		{"text[] DEFAULT '{}' NOT NULL", "text[]"},
+		{"timestamptz DEFAULT NOW()", "timestamptz"},
+		{"character varying(255) UNIQUE", "character varying(255)"},
+		{"jsonb", "jsonb"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := castType(tt.dataType); got != tt.want {
+			t.Errorf("castType(%q) = %q, want %q", tt.dataType, got, tt.want)
+		}
+	}
+}
+
+func TestTextArray(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{[]string{"a", "b"}, `{"a","b"}`, false},
+		{[]string{`say "hi"`, `back\slash`}, `{"say \"hi\"","back\\slash"}`, false},
+		{[2]int{1, 2}, `{"1","2"}`, false},
+		{[]string{}, `{}`, false},
+		{`{"a"}`, `{"a"}`, false},
+		{1, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := textArray(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("textArray(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("textArray(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}